@@ -0,0 +1,264 @@
+package prettylog
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ANSIToHTML converts text styled with ANSI escape sequences to HTML, e.g. to show colored
+// records in a browser. Text is HTML escaped, SGR colors and decorations become styled spans,
+// and OSC 8 hyperlinks become links. Other escape sequences are removed.
+//
+// Links with javascript, vbscript or data URLs are dropped and their text is kept.
+//
+// Reverse video swaps the colors. Default colors are taken from the --ansi-fg and --ansi-bg
+// CSS variables of the page, which default to light text on a dark background.
+func ANSIToHTML(s string) string {
+	var c ansiHTMLConverter
+	c.sb.Grow(len(s) + len(s)/2)
+	for i := 0; i < len(s); {
+		n := ansiSequenceLen(s[i:])
+		if n == 0 {
+			end := strings.IndexByte(s[i:], '\x1b')
+			if end == -1 {
+				end = len(s) - i
+			} else if end == 0 {
+				// A lone escape at the end of s.
+				end = 1
+			}
+			c.text(s[i : i+end])
+			i += end
+			continue
+		}
+		c.sequence(s[i : i+n])
+		i += n
+	}
+	c.closeSpan()
+	if c.link {
+		c.sb.WriteString("</a>")
+	}
+	return c.sb.String()
+}
+
+// The default colors of the page, used by reverse video to swap the default colors.
+const (
+	defaultHTMLForeground = "var(--ansi-fg,#e5e5e5)"
+	defaultHTMLBackground = "var(--ansi-bg,#1e1e1e)"
+)
+
+// htmlStyle is the SGR state of an [ansiHTMLConverter].
+type htmlStyle struct {
+	fg, bg    string
+	bold      bool
+	faint     bool
+	italic    bool
+	underline bool
+	strike    bool
+	reverse   bool
+}
+
+// css returns the CSS declarations of the style.
+func (st htmlStyle) css() string {
+	var decls []string
+	fg, bg := st.fg, st.bg
+	if st.reverse {
+		fg, bg = cmp.Or(bg, defaultHTMLBackground), cmp.Or(fg, defaultHTMLForeground)
+	}
+	if fg != "" {
+		decls = append(decls, "color:"+fg)
+	}
+	if bg != "" {
+		decls = append(decls, "background-color:"+bg)
+	}
+	if st.bold {
+		decls = append(decls, "font-weight:bold")
+	}
+	if st.faint {
+		decls = append(decls, "opacity:0.6")
+	}
+	if st.italic {
+		decls = append(decls, "font-style:italic")
+	}
+	switch {
+	case st.underline && st.strike:
+		decls = append(decls, "text-decoration:underline line-through")
+	case st.underline:
+		decls = append(decls, "text-decoration:underline")
+	case st.strike:
+		decls = append(decls, "text-decoration:line-through")
+	}
+	return strings.Join(decls, ";")
+}
+
+type ansiHTMLConverter struct {
+	sb       strings.Builder
+	style    htmlStyle
+	spanOpen bool
+	link     bool
+}
+
+func (c *ansiHTMLConverter) text(s string) {
+	if s == "" || s == "\x1b" {
+		return
+	}
+	if !c.spanOpen {
+		if css := c.style.css(); css != "" {
+			c.sb.WriteString(`<span style="`)
+			c.sb.WriteString(css)
+			c.sb.WriteString(`">`)
+			c.spanOpen = true
+		}
+	}
+	c.sb.WriteString(html.EscapeString(s))
+}
+
+func (c *ansiHTMLConverter) closeSpan() {
+	if c.spanOpen {
+		c.sb.WriteString("</span>")
+		c.spanOpen = false
+	}
+}
+
+func (c *ansiHTMLConverter) sequence(seq string) {
+	switch {
+	case strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m"):
+		c.closeSpan()
+		c.sgr(seq[2 : len(seq)-1])
+	case strings.HasPrefix(seq, "\x1b]8;"):
+		c.hyperlink(seq)
+	}
+}
+
+// hyperlink opens or closes a link from an OSC 8 sequence: ESC ] 8 ; params ; URL ST.
+func (c *ansiHTMLConverter) hyperlink(seq string) {
+	body := strings.TrimPrefix(seq, "\x1b]8;")
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "\a"), "\x1b\\")
+	_, link, _ := strings.Cut(body, ";")
+
+	c.closeSpan()
+	if c.link {
+		c.sb.WriteString("</a>")
+		c.link = false
+	}
+	if link == "" || !safeLinkURL(link) {
+		return
+	}
+	c.sb.WriteString(`<a href="`)
+	c.sb.WriteString(html.EscapeString(link))
+	c.sb.WriteString(`">`)
+	c.link = true
+}
+
+// safeLinkURL reports whether the URL can be used as a link without running code in the page.
+func safeLinkURL(link string) bool {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "javascript", "vbscript", "data":
+		return false
+	}
+	return true
+}
+
+// sgr applies the parameters of an SGR sequence to the style.
+func (c *ansiHTMLConverter) sgr(params string) {
+	if params == "" {
+		c.style = htmlStyle{}
+		return
+	}
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case code == 0:
+			c.style = htmlStyle{}
+		case code == 1:
+			c.style.bold = true
+		case code == 2:
+			c.style.faint = true
+		case code == 3:
+			c.style.italic = true
+		case code == 4:
+			c.style.underline = true
+		case code == 7:
+			c.style.reverse = true
+		case code == 9:
+			c.style.strike = true
+		case code == 22:
+			c.style.bold, c.style.faint = false, false
+		case code == 23:
+			c.style.italic = false
+		case code == 24:
+			c.style.underline = false
+		case code == 27:
+			c.style.reverse = false
+		case code == 29:
+			c.style.strike = false
+		case code >= 30 && code <= 37:
+			c.style.fg = paletteColor(code - 30)
+		case code >= 90 && code <= 97:
+			c.style.fg = paletteColor(code - 90 + 8)
+		case code >= 40 && code <= 47:
+			c.style.bg = paletteColor(code - 40)
+		case code >= 100 && code <= 107:
+			c.style.bg = paletteColor(code - 100 + 8)
+		case code == 39:
+			c.style.fg = ""
+		case code == 49:
+			c.style.bg = ""
+		case code == 38 || code == 48:
+			color, consumed := extendedColor(codes[i+1:])
+			i += consumed
+			if code == 38 {
+				c.style.fg = color
+			} else {
+				c.style.bg = color
+			}
+		}
+	}
+}
+
+// extendedColor parses the parameters following 38 or 48: "5;n" for the 256 colors palette or
+// "2;r;g;b" for RGB. It returns the CSS color and the number of parameters consumed.
+func extendedColor(codes []string) (string, int) {
+	if len(codes) == 0 {
+		return "", 0
+	}
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return min(max(n, 0), 255)
+	}
+	switch codes[0] {
+	case "5":
+		if len(codes) < 2 {
+			return "", len(codes)
+		}
+		r, g, b := xterm256ToRGB(atoi(codes[1]))
+		return rgbColor(r, g, b), 2
+	case "2":
+		if len(codes) < 4 {
+			return "", len(codes)
+		}
+		return rgbColor(atoi(codes[1]), atoi(codes[2]), atoi(codes[3])), 4
+	default:
+		return "", 1
+	}
+}
+
+func paletteColor(index int) string {
+	c := ansi16Palette[index]
+	return rgbColor(c[0], c[1], c[2])
+}
+
+func rgbColor(r, g, b int) string {
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
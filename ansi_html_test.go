@@ -0,0 +1,38 @@
+package prettylog
+
+import "testing"
+
+func TestANSIToHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "a < b & c", "a &lt; b &amp; c"},
+		{"basic color", "\x1b[31mred\x1b[0m text", `<span style="color:#cd0000">red</span> text`},
+		{
+			"bold background",
+			"\x1b[42;97;1m INFO \x1b[0;39;22m",
+			`<span style="color:#ffffff;background-color:#00cd00;font-weight:bold"> INFO </span>`,
+		},
+		{"256 colors", "\x1b[38;5;196mx\x1b[39m", `<span style="color:#ff0000">x</span>`},
+		{"rgb", "\x1b[38;2;255;135;0;4mx\x1b[0m", `<span style="color:#ff8700;text-decoration:underline">x</span>`},
+		{"reverse", "\x1b[31;7mx\x1b[27my", `<span style="color:var(--ansi-bg,#1e1e1e);background-color:#cd0000">x</span><span style="color:#cd0000">y</span>`},
+		{"reverse default colors", "\x1b[7mx\x1b[0m", `<span style="color:var(--ansi-bg,#1e1e1e);background-color:var(--ansi-fg,#e5e5e5)">x</span>`},
+		{"partial reset", "\x1b[1;33ma\x1b[22mb", `<span style="color:#cdcd00;font-weight:bold">a</span><span style="color:#cdcd00">b</span>`},
+		{
+			"hyperlink",
+			"\x1b]8;;https://example.com/?a=1&b=2\x1b\\\x1b[90mmain.go:12\x1b[0m\x1b]8;;\x1b\\",
+			`<a href="https://example.com/?a=1&amp;b=2"><span style="color:#7f7f7f">main.go:12</span></a>`,
+		},
+		{"unsafe hyperlink", "\x1b]8;;javascript:alert(1)\aclick\x1b]8;;\a", "click"},
+		{"other sequences", "\x1b[2Kline\x1b", "line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ANSIToHTML(tt.input); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package prettylog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy determines what happens when the queue of an asynchronous handler is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the logging goroutine until there is room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the record being logged.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest record in the queue to make room for the record being logged.
+	OverflowDropOldest
+)
+
+// String implements [fmt.Stringer] interface.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	default:
+		return "unknown"
+	}
+}
+
+const defaultAsyncQueueSize = 1024
+
+// asyncWriter writes rendered records to their destination in a background goroutine.
+//
+// asyncWriter is shared between a handler and its clones (e.g. from [Handler.WithAttrs]),
+// so every entry carries the destination and the pool of the handler that rendered it.
+type asyncWriter struct {
+	policy  OverflowPolicy
+	queue   chan asyncEntry
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	// mu guards closed and sending to queue. Senders hold the read lock,
+	// so closing the queue waits for in-flight sends.
+	mu     sync.RWMutex
+	closed bool
+
+	pendingMu   sync.Mutex
+	pendingCond *sync.Cond
+	pending     int
+	err         error
+}
+
+type asyncEntry struct {
+	buf    *bytes.Buffer
+	writer WriteLocker
+	pool   *limitedPool
+}
+
+func newAsyncWriter(queueSize int, policy OverflowPolicy) *asyncWriter {
+	if queueSize <= 0 {
+		queueSize = defaultAsyncQueueSize
+	}
+	aw := &asyncWriter{
+		policy: policy,
+		queue:  make(chan asyncEntry, queueSize),
+		done:   make(chan struct{}),
+	}
+	aw.pendingCond = sync.NewCond(&aw.pendingMu)
+	go aw.run()
+	return aw
+}
+
+// enqueue hands the entry to the background goroutine.
+//
+// It returns false if the writer is closed, in which case the caller
+// still owns the entry and should write it synchronously.
+func (aw *asyncWriter) enqueue(e asyncEntry) bool {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+	if aw.closed {
+		return false
+	}
+	aw.addPending(1)
+	switch aw.policy {
+	case OverflowDropNewest:
+		select {
+		case aw.queue <- e:
+		default:
+			aw.drop(e)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case aw.queue <- e:
+				return true
+			default:
+			}
+			select {
+			case old := <-aw.queue:
+				aw.drop(old)
+			default:
+			}
+		}
+	default:
+		aw.queue <- e
+	}
+	return true
+}
+
+func (aw *asyncWriter) run() {
+	defer close(aw.done)
+	for e := range aw.queue {
+		e.writer.Lock()
+		_, err := io.Copy(e.writer, e.buf)
+		e.writer.Unlock()
+		e.pool.Put(e.buf)
+		if err != nil {
+			aw.pendingMu.Lock()
+			if aw.err == nil {
+				aw.err = err
+			}
+			aw.pendingMu.Unlock()
+		}
+		aw.addPending(-1)
+	}
+}
+
+func (aw *asyncWriter) drop(e asyncEntry) {
+	aw.dropped.Add(1)
+	e.pool.Put(e.buf)
+	aw.addPending(-1)
+}
+
+func (aw *asyncWriter) addPending(delta int) {
+	aw.pendingMu.Lock()
+	aw.pending += delta
+	if aw.pending == 0 {
+		aw.pendingCond.Broadcast()
+	}
+	aw.pendingMu.Unlock()
+}
+
+// flush waits until every entry queued so far is written or dropped, and returns
+// the first write error encountered since the previous flush.
+func (aw *asyncWriter) flush() error {
+	aw.pendingMu.Lock()
+	defer aw.pendingMu.Unlock()
+	for aw.pending > 0 {
+		aw.pendingCond.Wait()
+	}
+	err := aw.err
+	aw.err = nil
+	return err
+}
+
+// close stops accepting new entries, drains the queue and stops the background goroutine.
+func (aw *asyncWriter) close() error {
+	aw.mu.Lock()
+	if !aw.closed {
+		aw.closed = true
+		close(aw.queue)
+	}
+	aw.mu.Unlock()
+	<-aw.done
+	return aw.flush()
+}
@@ -0,0 +1,168 @@
+package prettylog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// blockingWriter blocks every Write until release is closed.
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (bw *blockingWriter) Write(p []byte) (int, error) {
+	bw.once.Do(func() { close(bw.started) })
+	<-bw.release
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.buf.Write(p)
+}
+
+func (bw *blockingWriter) String() string {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.buf.String()
+}
+
+func TestAsyncFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(DefaultMessageWriter, DefaultNewLineWriter),
+		WithAsync(16, OverflowBlock),
+	)
+	defer handler.Close()
+
+	logger := slog.New(handler)
+	for range 10 {
+		logger.Info("message")
+	}
+	if err := handler.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Count(buf.String(), "message"); got != 10 {
+		t.Errorf("expected 10 messages, got %d", got)
+	}
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	out := newBlockingWriter()
+	handler := New(
+		WithOutput(out),
+		WithColor(false),
+		WithWriters(DefaultMessageWriter),
+		WithAsync(2, OverflowDropNewest),
+	)
+	logger := slog.New(handler)
+
+	logger.Info("first")
+	<-out.started // "first" is held by the background goroutine, the queue is empty.
+	logger.Info("second")
+	logger.Info("third")
+	logger.Info("fourth") // queue is full, dropped.
+	close(out.release)
+
+	if err := handler.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "firstsecondthird" {
+		t.Errorf("unexpected output %q", got)
+	}
+	if handler.Dropped() != 1 {
+		t.Errorf("expected 1 dropped record, got %d", handler.Dropped())
+	}
+}
+
+func TestAsyncDropOldest(t *testing.T) {
+	out := newBlockingWriter()
+	handler := New(
+		WithOutput(out),
+		WithColor(false),
+		WithWriters(DefaultMessageWriter),
+		WithAsync(2, OverflowDropOldest),
+	)
+	logger := slog.New(handler)
+
+	logger.Info("first")
+	<-out.started
+	logger.Info("second") // dropped to make room for "fourth".
+	logger.Info("third")
+	logger.Info("fourth")
+	close(out.release)
+
+	if err := handler.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "firstthirdfourth" {
+		t.Errorf("unexpected output %q", got)
+	}
+	if handler.Dropped() != 1 {
+		t.Errorf("expected 1 dropped record, got %d", handler.Dropped())
+	}
+}
+
+func TestAsyncCloseFallsBackToSync(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(DefaultMessageWriter),
+		WithAsync(4, OverflowBlock),
+	)
+	logger := slog.New(handler).With("key", "value")
+	logger.Info("before")
+	if err := handler.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := handler.Close(); err != nil {
+		t.Fatalf("closing twice should not fail: %v", err)
+	}
+	logger.Info("after")
+
+	if got := buf.String(); got != "beforeafter" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestAsyncWriteError(t *testing.T) {
+	handler := New(
+		WithOutput(&errorWriter{err: errors.New("write failed")}),
+		WithWriters(DefaultMessageWriter),
+		WithAsync(4, OverflowBlock),
+	)
+	defer handler.Close()
+	slog.New(handler).Info("message")
+
+	if err := handler.Flush(); err == nil || err.Error() != "write failed" {
+		t.Errorf("expected write error, got %v", err)
+	}
+	if err := handler.Flush(); err != nil {
+		t.Errorf("expected error to be reset after flush, got %v", err)
+	}
+}
+
+func TestSyncHandlerFlushCloseNoop(t *testing.T) {
+	handler := New(WithOutput(&bytes.Buffer{}))
+	if err := handler.Flush(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := handler.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if handler.Dropped() != 0 {
+		t.Errorf("expected no dropped records")
+	}
+}
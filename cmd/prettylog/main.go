@@ -0,0 +1,344 @@
+// Command prettylog prettifies JSON logs produced by [slog.JSONHandler].
+//
+// It reads newline-delimited JSON from stdin or the given files, reconstructs the
+// log records from the standard slog keys (time, level, msg and source), and renders
+// them with the default prettylog writers. Lines that are not JSON objects are
+// written untouched.
+//
+// Usage:
+//
+//	prettylog [flags] [file ...]
+//
+// Flags:
+//
+//	--color=auto|always|never   Colorize the output (default "auto"). In auto mode, NO_COLOR,
+//	                            FORCE_COLOR, CLICOLOR and CLICOLOR_FORCE are honored.
+//	--package=name              Package name used to shorten function names
+//	--theme=name                Color theme: default, dark, light, solarized or monochrome-bold
+//	--levels=NAME=N,...         Names of custom levels, e.g. "TRACE=-8,FATAL=12"
+//
+// Example:
+//
+//	go run ./myservice 2>&1 | prettylog
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tigorlazuardi/prettylog"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("prettylog", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	colorMode := flags.String("color", "auto", "colorize the output: auto, always or never")
+	packageName := flags.String("package", "", "package name used to shorten function names")
+	themeName := flags.String("theme", prettylog.DefaultTheme.Name, "color theme: default, dark, light, solarized or monochrome-bold")
+	levelNames := flags.String("levels", "", `names of custom levels, e.g. "TRACE=-8,FATAL=12"`)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: prettylog [flags] [file ...]")
+		fmt.Fprintln(stderr, "Prettifies newline-delimited JSON logs from slog.JSONHandler. Reads stdin if no file is given.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var depth prettylog.ColorDepth
+	switch *colorMode {
+	case "auto":
+		depth = prettylog.DetectColorDepth(stdout)
+	case "always":
+		depth = max(prettylog.DetectColorDepth(stdout), prettylog.Color16)
+	case "never":
+		depth = prettylog.ColorNone
+	default:
+		fmt.Fprintf(stderr, "prettylog: invalid --color value %q, must be auto, always or never\n", *colorMode)
+		return 2
+	}
+
+	theme, ok := prettylog.Themes[*themeName]
+	if !ok {
+		fmt.Fprintf(stderr, "prettylog: unknown --theme %q\n", *themeName)
+		return 2
+	}
+
+	names, err := parseLevelNames(*levelNames)
+	if err != nil {
+		fmt.Fprintf(stderr, "prettylog: invalid --levels: %v\n", err)
+		return 2
+	}
+
+	out := prettylog.WrapWriteLocker(stdout)
+	handler := prettylog.New(
+		prettylog.WithOutput(out),
+		prettylog.WithColorDepth(depth),
+		prettylog.WithPackageName(*packageName),
+		prettylog.WithTheme(theme),
+		prettylog.WithLevelNames(names),
+	)
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	status := 0
+	for _, name := range files {
+		if err := prettifyFile(name, stdin, out, handler); err != nil {
+			fmt.Fprintf(stderr, "prettylog: %v\n", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+func prettifyFile(name string, stdin io.Reader, out prettylog.WriteLocker, handler *prettylog.Handler) error {
+	if name == "-" {
+		return prettify(stdin, out, handler)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return prettify(f, out, handler)
+}
+
+// prettify renders every line of r to out. Lines that are not JSON objects are written untouched.
+func prettify(r io.Reader, out prettylog.WriteLocker, handler *prettylog.Handler) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if err := prettifyLine(line, out, handler); err != nil {
+				return err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+func prettifyLine(line []byte, out prettylog.WriteLocker, handler *prettylog.Handler) error {
+	ctx, record, ok := parseRecord(bytes.TrimSpace(line), handler.Levels())
+	if ok {
+		return handler.Handle(ctx, record)
+	}
+	out.Lock()
+	defer out.Unlock()
+	if _, err := out.Write(line); err != nil {
+		return err
+	}
+	if line[len(line)-1] != '\n' {
+		_, err := out.Write([]byte{'\n'})
+		return err
+	}
+	return nil
+}
+
+// parseRecord reconstructs a slog.Record from a line produced by slog.JSONHandler.
+//
+// Level names are parsed with levels, so custom level names map back to their levels.
+//
+// The source, if present, is returned as a frame in the context, see [prettylog.ContextWithFrame].
+func parseRecord(line []byte, levels *prettylog.Levels) (context.Context, slog.Record, bool) {
+	ctx := context.Background()
+	if len(line) == 0 || line[0] != '{' {
+		return ctx, slog.Record{}, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	attrs, err := decodeObject(dec)
+	if err != nil || dec.More() {
+		return ctx, slog.Record{}, false
+	}
+
+	var (
+		t      time.Time
+		level  = slog.LevelInfo
+		msg    string
+		frame  runtime.Frame
+		hasSrc bool
+		rest   = make([]slog.Attr, 0, len(attrs))
+	)
+	for _, a := range attrs {
+		switch a.Key {
+		case slog.TimeKey:
+			if a.Value.Kind() == slog.KindString {
+				if parsed, err := time.Parse(time.RFC3339Nano, a.Value.String()); err == nil {
+					t = parsed
+					continue
+				}
+			}
+		case slog.LevelKey:
+			if a.Value.Kind() == slog.KindString {
+				if parsed, err := levels.Parse(a.Value.String()); err == nil {
+					level = parsed
+					continue
+				}
+			}
+		case slog.MessageKey:
+			if a.Value.Kind() == slog.KindString {
+				msg = a.Value.String()
+				continue
+			}
+		case slog.SourceKey:
+			if a.Value.Kind() == slog.KindGroup {
+				frame, hasSrc = parseSource(a.Value.Group())
+				if hasSrc {
+					continue
+				}
+			}
+		}
+		rest = append(rest, a)
+	}
+
+	record := slog.NewRecord(t, level, msg, 0)
+	record.AddAttrs(rest...)
+	if hasSrc {
+		ctx = prettylog.ContextWithFrame(ctx, frame)
+	}
+	return ctx, record, true
+}
+
+// parseSource parses the source group written by slog.JSONHandler
+// (keys function, file and line) into a frame.
+func parseSource(attrs []slog.Attr) (runtime.Frame, bool) {
+	var frame runtime.Frame
+	for _, a := range attrs {
+		switch a.Key {
+		case "function":
+			frame.Function = a.Value.String()
+		case "file":
+			frame.File = a.Value.String()
+		case "line":
+			if a.Value.Kind() == slog.KindInt64 {
+				frame.Line = int(a.Value.Int64())
+			}
+		}
+	}
+	return frame, frame.File != "" || frame.Function != ""
+}
+
+// decodeObject decodes a JSON object into attributes, preserving the order of the keys.
+// Nested objects become groups.
+func decodeObject(dec *json.Decoder) ([]slog.Attr, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected JSON object, got %v", tok)
+	}
+	var attrs []slog.Attr
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected object key, got %v", tok)
+		}
+		value, err := decodeValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: value})
+	}
+	// Consume the closing brace.
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+func decodeValue(dec *json.Decoder) (slog.Value, error) {
+	if !dec.More() {
+		return slog.Value{}, errors.New("unexpected end of JSON")
+	}
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return slog.Value{}, err
+	}
+	switch {
+	case len(raw) > 0 && raw[0] == '{':
+		inner := json.NewDecoder(bytes.NewReader(raw))
+		inner.UseNumber()
+		attrs, err := decodeObject(inner)
+		if err != nil {
+			return slog.Value{}, err
+		}
+		return slog.GroupValue(attrs...), nil
+	case len(raw) > 0 && raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.StringValue(s), nil
+	case string(raw) == "true" || string(raw) == "false":
+		return slog.BoolValue(string(raw) == "true"), nil
+	case string(raw) == "null":
+		return slog.AnyValue(nil), nil
+	case len(raw) > 0 && raw[0] == '[':
+		var v []any
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.AnyValue(v), nil
+	default:
+		n := json.Number(strings.TrimSpace(string(raw)))
+		if i, err := n.Int64(); err == nil {
+			return slog.Int64Value(i), nil
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return slog.Value{}, err
+		}
+		return slog.Float64Value(f), nil
+	}
+}
+
+// parseLevelNames parses a comma separated list of NAME=LEVEL pairs, e.g. "TRACE=-8,FATAL=12".
+func parseLevelNames(s string) (map[slog.Level]string, error) {
+	names := make(map[slog.Level]string)
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q is not NAME=LEVEL", pair)
+		}
+		lvl, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q: level must be an integer", pair)
+		}
+		names[slog.Level(lvl)] = name
+	}
+	return names, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tigorlazuardi/prettylog"
+)
+
+func TestParseRecord(t *testing.T) {
+	line := `{"time":"2024-01-02T03:04:05.5Z","level":"WARN","msg":"hello","source":{"function":"main.main","file":"/app/main.go","line":12},"user":{"id":1,"name":"a"},"ratio":0.5}`
+	ctx, record, ok := parseRecord([]byte(line), nil)
+	if !ok {
+		t.Fatal("expected line to be parsed")
+	}
+	if !record.Time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 5e8, time.UTC)) {
+		t.Errorf("unexpected time %v", record.Time)
+	}
+	if record.Level != slog.LevelWarn {
+		t.Errorf("unexpected level %v", record.Level)
+	}
+	if record.Message != "hello" {
+		t.Errorf("unexpected message %q", record.Message)
+	}
+	frame, ok := prettylog.FrameFromContext(ctx)
+	if !ok || frame.Function != "main.main" || frame.File != "/app/main.go" || frame.Line != 12 {
+		t.Errorf("unexpected frame %+v", frame)
+	}
+
+	var keys []string
+	record.Attrs(func(a slog.Attr) bool {
+		keys = append(keys, a.Key+":"+a.Value.Kind().String())
+		return true
+	})
+	if got := strings.Join(keys, ","); got != "user:Group,ratio:Float64" {
+		t.Errorf("unexpected attributes %s", got)
+	}
+}
+
+func TestParseRecordCustomLevel(t *testing.T) {
+	_, record, ok := parseRecord([]byte(`{"level":"DEBUG-4","msg":"trace"}`), nil)
+	if !ok {
+		t.Fatal("expected line to be parsed")
+	}
+	if record.Level != slog.LevelDebug-4 {
+		t.Errorf("unexpected level %v", record.Level)
+	}
+}
+
+func TestParseRecordNotJSON(t *testing.T) {
+	for _, line := range []string{"plain text", "[1,2,3]", `{"broken":`, `{"a":1} trailing`} {
+		if _, _, ok := parseRecord([]byte(line), nil); ok {
+			t.Errorf("expected %q not to be parsed", line)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(file, []byte(`{"level":"ERROR","msg":"from file"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdin := strings.NewReader("starting up\n" + `{"level":"INFO","msg":"from stdin","key":"value"}` + "\n")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	if code := run([]string{"--color=never", "-", file}, stdin, stdout, stderr); code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"starting up\n", "INFO from stdin", `"key": "value"`, "ERROR from file"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("expected no color codes, got %q", out)
+	}
+}
+
+func TestRunInvalidColor(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	if code := run([]string{"--color=sometimes"}, strings.NewReader(""), &bytes.Buffer{}, stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
+
+func TestRunInvalidTheme(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	if code := run([]string{"--theme=neon"}, strings.NewReader(""), &bytes.Buffer{}, stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
+
+func TestRunLevelNames(t *testing.T) {
+	stdin := strings.NewReader(`{"level":"TRACE","msg":"tracing"}` + "\n" + `{"level":"FATAL","msg":"dying"}` + "\n")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	if code := run([]string{"--color=never", "--levels=TRACE=-8,FATAL=12"}, stdin, stdout, stderr); code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"TRACE tracing", "FATAL dying"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `"level"`) {
+		t.Errorf("expected level names to be parsed, got:\n%s", out)
+	}
+}
+
+func TestRunInvalidLevelNames(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	if code := run([]string{"--levels=TRACE"}, strings.NewReader(""), &bytes.Buffer{}, stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+}
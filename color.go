@@ -0,0 +1,371 @@
+package prettylog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// ColorDepth is the number of colors supported by the output.
+//
+// It is available to stylers through [RecordData.ColorDepth], so they can degrade
+// gracefully on terminals with fewer colors. See [Style.Degrade].
+type ColorDepth int
+
+const (
+	// ColorNone means the output does not support colors.
+	ColorNone ColorDepth = iota
+	// Color16 means the output supports the 16 basic ANSI colors.
+	Color16
+	// Color256 means the output supports the 256 colors xterm palette.
+	Color256
+	// ColorTrueColor means the output supports 24-bit RGB colors.
+	ColorTrueColor
+)
+
+// String implements [fmt.Stringer] interface.
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorNone:
+		return "none"
+	case Color16:
+		return "16"
+	case Color256:
+		return "256"
+	case ColorTrueColor:
+		return "truecolor"
+	default:
+		return "unknown"
+	}
+}
+
+// DetectColorDepth determines the color depth of the given writer from the environment
+// and whether the writer is a terminal.
+//
+// The following conventions are honored, in order:
+//   - FORCE_COLOR: "0" or "false" disables colors. Any other value enables colors even
+//     if the writer is not a terminal: "2" for 256 colors, "3" for truecolor, and
+//     16 colors otherwise (or more if detected from TERM and COLORTERM).
+//   - NO_COLOR: if set to a non-empty value, disables colors. See https://no-color.org.
+//   - CLICOLOR_FORCE: if set to a non-empty value other than "0", enables colors even
+//     if the writer is not a terminal.
+//   - TERM=dumb disables colors.
+//   - Colors are disabled if the writer is not a terminal (see [CanColor] for how the writer is inspected).
+//   - CLICOLOR=0 disables colors.
+//
+// When colors are enabled, the depth is truecolor if COLORTERM is "truecolor" or "24bit",
+// 256 colors if TERM contains "256color", and 16 colors otherwise.
+func DetectColorDepth(w io.Writer) ColorDepth {
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		switch strings.ToLower(force) {
+		case "0", "false":
+			return ColorNone
+		case "2":
+			return max(Color256, envColorDepth())
+		case "3":
+			return ColorTrueColor
+		default:
+			return envColorDepth()
+		}
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorNone
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return envColorDepth()
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return ColorNone
+	}
+	if !isTerminal(w) {
+		return ColorNone
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return ColorNone
+	}
+	return envColorDepth()
+}
+
+// envColorDepth returns the color depth advertised by COLORTERM and TERM. It is at least [Color16].
+func envColorDepth() ColorDepth {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrueColor
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case strings.HasSuffix(term, "-direct"), strings.Contains(term, "truecolor"):
+		return ColorTrueColor
+	case strings.Contains(term, "256color"):
+		return Color256
+	default:
+		return Color16
+	}
+}
+
+// isTerminal reports whether w, or the writer it wraps, is a terminal.
+func isTerminal(w io.Writer) bool {
+	fd, ok := fdOf(w)
+	return ok && (isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd))
+}
+
+// Degrade returns the style with extended colors converted to the closest colors
+// supported by depth.
+//
+// Extended colors are written as SGR sequences in the style, e.g.
+// Style{38, 5, 208} for the 256 colors palette, or Style{38, 2, 255, 135, 0} for RGB.
+// Basic attributes are left untouched. If depth is [ColorNone], nil is returned.
+func (s Style) Degrade(depth ColorDepth) Style {
+	if depth == ColorNone {
+		return nil
+	}
+	if depth >= ColorTrueColor {
+		return s
+	}
+	var out Style
+	for i := 0; i < len(s); i++ {
+		attr := s[i]
+		if (attr != 38 && attr != 48) || i+1 >= len(s) {
+			out = append(out, attr)
+			continue
+		}
+		background := attr == 48
+		switch {
+		case s[i+1] == 5 && i+2 < len(s):
+			index := int(s[i+2])
+			i += 2
+			if depth >= Color256 {
+				out = append(out, attr, 5, color.Attribute(index))
+				continue
+			}
+			r, g, b := xterm256ToRGB(index)
+			out = append(out, ansi16Attribute(r, g, b, background))
+		case s[i+1] == 2 && i+4 < len(s):
+			r, g, b := int(s[i+2]), int(s[i+3]), int(s[i+4])
+			i += 4
+			if depth >= Color256 {
+				out = append(out, attr, 5, color.Attribute(rgbToXterm256(r, g, b)))
+				continue
+			}
+			out = append(out, ansi16Attribute(r, g, b, background))
+		default:
+			out = append(out, attr)
+		}
+	}
+	return out
+}
+
+// ansi16Palette are the RGB values of the 16 basic colors, as rendered by xterm.
+var ansi16Palette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// ansi16Attribute returns the basic color attribute closest to the RGB color.
+func ansi16Attribute(r, g, b int, background bool) color.Attribute {
+	best, bestDistance := 0, -1
+	for i, c := range ansi16Palette {
+		dr, dg, db := r-c[0], g-c[1], b-c[2]
+		if distance := dr*dr + dg*dg + db*db; bestDistance == -1 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	base := color.FgBlack
+	if best >= 8 {
+		base = color.FgHiBlack
+		best -= 8
+	}
+	if background {
+		base += color.BgBlack - color.FgBlack
+	}
+	return base + color.Attribute(best)
+}
+
+// xterm256ToRGB returns the RGB values of a color of the 256 colors palette.
+func xterm256ToRGB(index int) (r, g, b int) {
+	switch {
+	case index < 16:
+		c := ansi16Palette[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		index -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return level(index / 36), level(index / 6 % 6), level(index % 6)
+	default:
+		gray := 8 + (index-232)*10
+		return gray, gray, gray
+	}
+}
+
+// rgbToXterm256 returns the color of the 256 colors palette closest to the RGB color.
+func rgbToXterm256(r, g, b int) int {
+	cube := func(v int) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (v - 35) / 40
+	}
+	ci := 16 + 36*cube(r) + 6*cube(g) + cube(b)
+	cr, cg, cb := xterm256ToRGB(ci)
+
+	average := (r + g + b) / 3
+	gi := 232 + min(max((average-8+5)/10, 0), 23)
+	gr, gg, gb := xterm256ToRGB(gi)
+
+	distance := func(r2, g2, b2 int) int {
+		dr, dg, db := r-r2, g-g2, b-b2
+		return dr*dr + dg*dg + db*db
+	}
+	if distance(gr, gg, gb) < distance(cr, cg, cb) {
+		return gi
+	}
+	return ci
+}
+
+// faintStyle is the style of secondary lines, like stack frames outside of the main module.
+var faintStyle = Style{color.Faint}
+
+// writeStyledLine writes line to the buffer of the record, styled by style if [RecordData.Color] is true.
+func writeStyledLine(info RecordData, style Style, line string) {
+	if !info.Color {
+		info.Buffer.WriteString(line)
+		return
+	}
+	ansiSequenceOf(info, style, false).write(info.Buffer, line)
+}
+
+// ansiSequence is the pair of SGR escape sequences written around styled text.
+type ansiSequence struct {
+	prefix string
+	suffix string
+}
+
+// write writes s to buf, wrapped in the escape sequences.
+func (seq ansiSequence) write(buf *bytes.Buffer, s string) {
+	buf.WriteString(seq.prefix)
+	buf.WriteString(s)
+	buf.WriteString(seq.suffix)
+}
+
+// newANSISequence returns the escape sequences of attrs, as written by [color.Color.Sprint].
+//
+// Like fatih/color, every attribute is reset by its own reset code, or 0 when it has none.
+func newANSISequence(attrs []color.Attribute) ansiSequence {
+	var prefix, suffix strings.Builder
+	prefix.WriteString("\x1b[")
+	suffix.WriteString("\x1b[")
+	for i, attr := range attrs {
+		if i > 0 {
+			prefix.WriteByte(';')
+			suffix.WriteByte(';')
+		}
+		prefix.WriteString(strconv.Itoa(int(attr)))
+		suffix.WriteString(strconv.Itoa(int(resetAttribute(attr))))
+	}
+	prefix.WriteByte('m')
+	suffix.WriteByte('m')
+	return ansiSequence{prefix: prefix.String(), suffix: suffix.String()}
+}
+
+// resetAttribute returns the attribute resetting attr, following fatih/color.
+func resetAttribute(attr color.Attribute) color.Attribute {
+	switch attr {
+	case color.Bold, color.Faint:
+		return color.ResetBold
+	case color.Italic:
+		return color.ResetItalic
+	case color.Underline:
+		return color.ResetUnderline
+	case color.BlinkSlow, color.BlinkRapid:
+		return color.ResetBlinking
+	case color.ReverseVideo:
+		return color.ResetReversed
+	case color.Concealed:
+		return color.ResetConcealed
+	case color.CrossedOut:
+		return color.ResetCrossedOut
+	default:
+		return color.Reset
+	}
+}
+
+const (
+	// maxCachedStyleLen is the number of attributes of the longest style kept in the sequence cache.
+	// It fits a foreground and a background RGB color with a few decorations.
+	maxCachedStyleLen = 16
+	// maxCachedSequences bounds the sequence cache, for stylers building styles on the fly.
+	maxCachedSequences = 1024
+)
+
+// ansiKey identifies the escape sequences of a style at a color depth.
+type ansiKey struct {
+	depth ColorDepth
+	bold  bool
+	len   int
+	attrs [maxCachedStyleLen]color.Attribute
+}
+
+// ansiCache holds the escape sequences computed so far, so styling a record does not
+// degrade styles and format escape sequences again.
+var ansiCache = struct {
+	sync.RWMutex
+	sequences map[ansiKey]ansiSequence
+}{sequences: make(map[ansiKey]ansiSequence)}
+
+// ansiSequenceOf returns the escape sequences of style, degraded to the color depth of the record.
+// If bold is true, [color.Bold] is added after the style attributes.
+//
+// Stylers are only called when [RecordData.Color] is true, so colors are enabled regardless
+// of [color.NoColor], which is detected by fatih/color from stdout only.
+func ansiSequenceOf(info RecordData, style Style, bold bool) ansiSequence {
+	depth := info.ColorDepth
+	if depth == ColorNone {
+		depth = Color16
+	}
+	if len(style) > maxCachedStyleLen {
+		return computeANSISequence(style, depth, bold)
+	}
+	key := ansiKey{depth: depth, bold: bold, len: len(style)}
+	copy(key.attrs[:], style)
+
+	ansiCache.RLock()
+	seq, ok := ansiCache.sequences[key]
+	ansiCache.RUnlock()
+	if ok {
+		return seq
+	}
+
+	seq = computeANSISequence(style, depth, bold)
+	ansiCache.Lock()
+	if len(ansiCache.sequences) < maxCachedSequences {
+		ansiCache.sequences[key] = seq
+	}
+	ansiCache.Unlock()
+	return seq
+}
+
+func computeANSISequence(style Style, depth ColorDepth, bold bool) ansiSequence {
+	// Clipped, so adding bold does not write to the backing array of the style.
+	attrs := slices.Clip(style.Degrade(depth))
+	if bold {
+		attrs = append(attrs, color.Bold)
+	}
+	return newANSISequence(attrs)
+}
@@ -0,0 +1,109 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// clearColorEnv unsets the color environment variables for the duration of the test.
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"FORCE_COLOR", "NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE", "TERM", "COLORTERM"} {
+		t.Setenv(key, "") // registers the restore of the original value
+		os.Unsetenv(key)
+	}
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected ColorDepth
+	}{
+		{"not a terminal", nil, ColorNone},
+		{"force color", map[string]string{"FORCE_COLOR": "1"}, Color16},
+		{"force color empty", map[string]string{"FORCE_COLOR": ""}, Color16},
+		{"force color 256", map[string]string{"FORCE_COLOR": "2"}, Color256},
+		{"force color truecolor", map[string]string{"FORCE_COLOR": "3"}, ColorTrueColor},
+		{"force color disabled", map[string]string{"FORCE_COLOR": "0", "CLICOLOR_FORCE": "1"}, ColorNone},
+		{"force color over no color", map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, Color16},
+		{"force color with colorterm", map[string]string{"FORCE_COLOR": "true", "COLORTERM": "truecolor"}, ColorTrueColor},
+		{"clicolor force", map[string]string{"CLICOLOR_FORCE": "1", "TERM": "xterm-256color"}, Color256},
+		{"clicolor force zero", map[string]string{"CLICOLOR_FORCE": "0"}, ColorNone},
+		{"no color over clicolor force", map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, ColorNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearColorEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := DetectColorDepth(&bytes.Buffer{}); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStyleDegrade(t *testing.T) {
+	orange256 := Style{color.Bold, 38, 5, 208}
+	orangeRGB := Style{48, 2, 255, 135, 0, color.Underline}
+
+	tests := []struct {
+		name     string
+		style    Style
+		depth    ColorDepth
+		expected Style
+	}{
+		{"truecolor keeps rgb", orangeRGB, ColorTrueColor, orangeRGB},
+		{"256 keeps palette", orange256, Color256, orange256},
+		{"rgb to 256", orangeRGB, Color256, Style{48, 5, 208, color.Underline}},
+		{"palette to 16", orange256, Color16, Style{color.Bold, color.FgYellow}},
+		{"rgb to 16 background", Style{48, 2, 200, 0, 0}, Color16, Style{color.BgRed}},
+		{"basic untouched", Style{color.FgRed, color.Bold}, Color16, Style{color.FgRed, color.Bold}},
+		{"none", orange256, ColorNone, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.style.Degrade(tt.depth); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStylersIgnoreGlobalNoColor(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	info := RecordData{
+		Record:     slog.NewRecord(time.Now(), slog.LevelError, "message", 0),
+		Color:      true,
+		ColorDepth: Color16,
+		Theme:      DefaultTheme,
+	}
+	if got := SimpleColoredStyler(info, "text"); got != "\x1b[31mtext\x1b[0m" {
+		t.Errorf("expected colored text when RecordData.Color is set, got %q", got)
+	}
+}
+
+func TestWithColorDepth(t *testing.T) {
+	h := New(WithColorDepth(Color256))
+	if !h.color || h.colorDepth != Color256 {
+		t.Errorf("expected color enabled with 256 colors, got %v %v", h.color, h.colorDepth)
+	}
+	h = New(WithColorDepth(ColorNone))
+	if h.color {
+		t.Error("expected color disabled")
+	}
+	if got := h.depthOf(true); got != Color16 {
+		t.Errorf("expected at least 16 colors when color is forced, got %v", got)
+	}
+}
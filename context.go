@@ -0,0 +1,34 @@
+package prettylog
+
+import (
+	"context"
+	"runtime"
+)
+
+type frameContextKey struct{}
+
+// ContextWithFrame returns a context that makes [Handler.Handle] use the given frame as
+// caller information ([RecordData.Frame]) instead of resolving [slog.Record.PC].
+//
+// This is useful to render records that did not originate from this process,
+// e.g. records parsed from JSON logs, where the source is known but no program counter is available.
+func ContextWithFrame(ctx context.Context, frame runtime.Frame) context.Context {
+	return context.WithValue(ctx, frameContextKey{}, frame)
+}
+
+// FrameFromContext returns the frame set by [ContextWithFrame].
+func FrameFromContext(ctx context.Context) (runtime.Frame, bool) {
+	if ctx == nil {
+		return runtime.Frame{}, false
+	}
+	frame, ok := ctx.Value(frameContextKey{}).(runtime.Frame)
+	return frame, ok
+}
+
+// hasCaller reports whether the frame carries caller information.
+//
+// [runtime.Frame.Func] is nil for inlined functions and for frames given by [ContextWithFrame],
+// so the file and function name are checked as well.
+func hasCaller(frame runtime.Frame) bool {
+	return frame.Func != nil || frame.File != "" || frame.Function != ""
+}
@@ -128,13 +128,29 @@ func ExampleTimeWriter() {
 		prettylog.ReplaceWriter(prettylog.DefaultTimeWriter, timeWriterOnly),
 	)
 
+	// Time elapsed since the handler was created, e.g. "+1.234s"
+	timeWriterElapsed := prettylog.NewTimeWriter().WithElapsedFormat()
+	handler4 := prettylog.New(
+		prettylog.ReplaceWriter(prettylog.DefaultTimeWriter, timeWriterElapsed),
+	)
+
+	// RFC3339 format in UTC, to compare with server logs
+	timeWriterUTC := prettylog.NewTimeWriter().WithRFC3339Format().WithUTC()
+	handler5 := prettylog.New(
+		prettylog.ReplaceWriter(prettylog.DefaultTimeWriter, timeWriterUTC),
+	)
+
 	logger1 := slog.New(handler1)
 	logger2 := slog.New(handler2)
 	logger3 := slog.New(handler3)
+	logger4 := slog.New(handler4)
+	logger5 := slog.New(handler5)
 
 	logger1.Info("RFC3339 timestamp")
 	logger2.Info("Custom timestamp format")
 	logger3.Info("Time-only timestamp")
+	logger4.Info("Elapsed timestamp")
+	logger5.Info("UTC timestamp")
 }
 
 // ExampleFunctionWriter demonstrates function name formatting options.
@@ -1,8 +1,6 @@
 package prettylog
 
 import (
-	"os"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -12,9 +10,9 @@ import (
 type Formatter func(info RecordData) string
 
 // DefaultLevelFormatter returns the string representation of the log level.
-// It uses the standard slog level string representation.
+// It uses the standard slog level string representation, or the custom names set by [WithLevelNames].
 func DefaultLevelFormatter(info RecordData) string {
-	return info.Record.Level.String()
+	return info.Levels.Name(info.Record.Level)
 }
 
 // TrimPrefixFunctionFormatter returns the function name with the package name prefix trimmed.
@@ -43,12 +41,7 @@ func ShortFileLineFormatter(info RecordData) string {
 	if info.Frame.Func == nil {
 		return ""
 	}
-	wd, _ := os.Getwd()
-	s, trimmed := strings.CutPrefix(info.Frame.File, wd)
-	if trimmed {
-		return strings.TrimPrefix(s, "/") + ":" + strconv.Itoa(info.Frame.Line)
-	}
-	return info.Frame.File + ":" + strconv.Itoa(info.Frame.Line)
+	return ShortFileLineFormat(info)
 }
 
 // FullFileLineFormatter returns the complete file path with line number.
@@ -57,7 +50,7 @@ func FullFileLineFormatter(info RecordData) string {
 	if info.Frame.Func == nil {
 		return ""
 	}
-	return info.Frame.File + ":" + strconv.Itoa(info.Frame.Line)
+	return LongFileLineFormat(info)
 }
 
 // TimeOnlyFormatter returns the time in time.TimeOnly format ("15:04:05").
@@ -0,0 +1,106 @@
+package prettylog
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultFrameCacheSize is the number of call sites cached by a [Handler]. See [WithFrameCacheSize].
+const DefaultFrameCacheSize = 4096
+
+// frameFormat identifies a string formatted from a frame and cached with it.
+type frameFormat int
+
+const (
+	formatShortFileLine frameFormat = iota
+	formatLongFileLine
+	formatModuleFileLine
+	formatShortFunction
+	formatCompactSource
+	frameFormatCount
+)
+
+// frameCache caches the frames of call sites by PC, along with the strings formatted from them,
+// so repeated log sites are not symbolized and formatted again. It is safe for concurrent use.
+//
+// When the cache is full, an arbitrary entry is evicted to make room for a new call site.
+type frameCache struct {
+	mu      sync.RWMutex
+	size    int
+	entries map[uintptr]*frameEntry
+}
+
+// frameEntry is the cached frame of a call site.
+type frameEntry struct {
+	frame     runtime.Frame
+	formatted [frameFormatCount]atomic.Pointer[string]
+}
+
+// newFrameCache returns a cache of size call sites, or nil if size is 0 or less.
+func newFrameCache(size int) *frameCache {
+	if size <= 0 {
+		return nil
+	}
+	return &frameCache{
+		size:    size,
+		entries: make(map[uintptr]*frameEntry),
+	}
+}
+
+// reset returns an empty cache of the same size. A nil cache stays disabled.
+func (c *frameCache) reset() *frameCache {
+	if c == nil {
+		return nil
+	}
+	return newFrameCache(c.size)
+}
+
+// lookup returns the frame of pc and its cache entry. The entry is nil if the cache is disabled.
+func (c *frameCache) lookup(pc uintptr) (runtime.Frame, *frameEntry) {
+	if c == nil || pc == 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		return frame, nil
+	}
+	c.mu.RLock()
+	entry, ok := c.entries[pc]
+	c.mu.RUnlock()
+	if ok {
+		return entry.frame, entry
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	entry = &frameEntry{frame: frame}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, ok := c.entries[pc]; ok {
+		return existing.frame, existing
+	}
+	if len(c.entries) >= c.size {
+		for evicted := range c.entries {
+			delete(c.entries, evicted)
+			break
+		}
+	}
+	c.entries[pc] = entry
+	return frame, entry
+}
+
+// cachedFrameFormat returns the result of format for the caller of the record, cached with
+// the frame of the call site when the handler has a frame cache.
+//
+// Formatters are called directly for frames the entry does not belong to, e.g. frames given
+// by [ContextWithFrame] or records built by hand.
+func cachedFrameFormat(info RecordData, kind frameFormat, format Formatter) string {
+	entry := info.frameEntry
+	if entry == nil || entry.frame.PC != info.Frame.PC || entry.frame.File != info.Frame.File {
+		return format(info)
+	}
+	if s := entry.formatted[kind].Load(); s != nil {
+		return *s
+	}
+	s := format(info)
+	entry.formatted[kind].Store(&s)
+	return s
+}
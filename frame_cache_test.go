@@ -0,0 +1,116 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrameCache(t *testing.T) {
+	pcs := make([]uintptr, 3)
+	if n := runtime.Callers(1, pcs); n != len(pcs) {
+		t.Fatalf("expected %d callers, got %d", len(pcs), n)
+	}
+
+	cache := newFrameCache(2)
+	frame, entry := cache.lookup(pcs[0])
+	if !strings.HasSuffix(frame.Function, ".TestFrameCache") {
+		t.Errorf("expected frame of the test function, got %q", frame.Function)
+	}
+	if _, again := cache.lookup(pcs[0]); again != entry {
+		t.Error("expected the same entry for the same PC")
+	}
+
+	cache.lookup(pcs[1])
+	cache.lookup(pcs[2])
+	if len(cache.entries) != 2 {
+		t.Errorf("expected cache to be bounded to 2 entries, got %d", len(cache.entries))
+	}
+
+	var disabled *frameCache
+	if frame, entry := disabled.lookup(pcs[0]); entry != nil || frame.Function == "" {
+		t.Errorf("expected resolved frame without entry, got %q and %v", frame.Function, entry)
+	}
+}
+
+func TestCachedFrameFormat(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(0)
+	frame, entry := newFrameCache(1).lookup(pc)
+	info := RecordData{Frame: frame, frameEntry: entry}
+
+	calls := 0
+	format := func(info RecordData) string {
+		calls++
+		return info.Frame.Function
+	}
+	for range 3 {
+		if got := cachedFrameFormat(info, formatShortFunction, format); got != frame.Function {
+			t.Errorf("expected %q, got %q", frame.Function, got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected formatter to be called once, got %d", calls)
+	}
+
+	// A frame the entry does not belong to is formatted directly.
+	info.Frame = runtime.Frame{Function: "main.main", File: "main.go", Line: 1}
+	if got := cachedFrameFormat(info, formatShortFunction, format); got != "main.main" {
+		t.Errorf("expected formatted frame, got %q", got)
+	}
+}
+
+func TestHandlerFrameCache(t *testing.T) {
+	log := func(h slog.Handler) string {
+		buf := &bytes.Buffer{}
+		h.(*Handler).Clone(WithOutput(buf)).Handle(t.Context(), frameCacheRecord())
+		return buf.String()
+	}
+	writers := WithWriters(NewFunctionWriter().WithPrefix(NoPrefix).WithStaticKey(""), CompactNewLineWriter)
+
+	cached := New(WithColor(false), writers, WithPackageName("github.com/tigorlazuardi/prettylog"))
+	expected := "prettylog.frameCacheRecord\n"
+	for range 2 {
+		if got := log(cached); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	}
+	if got := log(cached.Clone(WithFrameCacheSize(0))); got != expected {
+		t.Errorf("expected %q without cache, got %q", expected, got)
+	}
+
+	// Short function names depend on the package name, so the cache is not shared.
+	expected = "github.com/tigorlazuardi/prettylog.frameCacheRecord\n"
+	if got := log(cached.Clone(WithPackageName(""))); got != expected {
+		t.Errorf("expected %q after changing the package name, got %q", expected, got)
+	}
+}
+
+func frameCacheRecord() slog.Record {
+	pc, _, _, _ := runtime.Caller(0)
+	return slog.NewRecord(time.Now(), slog.LevelInfo, "message", pc)
+}
+
+func BenchmarkHandlerFrameCache(b *testing.B) {
+	record := frameCacheRecord()
+	for _, size := range []int{DefaultFrameCacheSize, 0} {
+		name := "cached"
+		if size == 0 {
+			name = "disabled"
+		}
+		b.Run(name, func(b *testing.B) {
+			handler := New(
+				WithOutput(&bytes.Buffer{}),
+				WithColor(false),
+				WithFrameCacheSize(size),
+				WithWriters(DefaultFunctionWrtier, DefaultFileLineWriter, CompactNewLineWriter),
+			)
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = handler.Handle(b.Context(), record)
+			}
+		})
+	}
+}
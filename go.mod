@@ -6,9 +6,7 @@ require (
 	github.com/fatih/color v1.18.0
 	github.com/mattn/go-isatty v0.0.20
 	github.com/tidwall/pretty v1.2.1
+	golang.org/x/sys v0.25.0
 )
 
-require (
-	github.com/mattn/go-colorable v0.1.13 // indirect
-	golang.org/x/sys v0.25.0 // indirect
-)
+require github.com/mattn/go-colorable v0.1.13 // indirect
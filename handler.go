@@ -1,10 +1,13 @@
 package prettylog
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"runtime"
+	"slices"
 )
 
 // Handler is a slog.Handler that provides cutomizeable, modular logging capabilities.
@@ -12,67 +15,245 @@ import (
 // Handler only implements the slog.Handler interface and pass the actual writing to
 // a list of [EntryWriter]s. This allows for flexible and modular logging behavior.
 type Handler struct {
-	attrs  []slog.Attr
-	groups []string
-	writer WriteLocker
-	opts   *slog.HandlerOptions
-	color  bool
+	groups       []string
+	groupedAttrs []GroupedAttrs
+	writer       WriteLocker
+	opts         *slog.HandlerOptions
+	color        bool
+	colorDepth   ColorDepth
+	wrap         *wrapResolver
+	keyAlignment KeyAlignment
+	keyWidth     int
+	layout       Layout
 
 	writers []EntryWriter
 
 	packageName string
 	pool        *limitedPool
+	async       *asyncWriter
+	outputs     []handlerOutput
+	redactor    *redactor
+	levelRules  *levelRules
+	theme       *Theme
+	levels      *Levels
+	clock       *recordClock
+	frames      *frameCache
 }
 
 // Enabled implements [slog.Handler] interface.
+//
+// If level rules are set by [WithLevelRules], Enabled reports true for the lowest level
+// of all rules, and records are filtered by their caller in [Handler.Handle].
 func (h *Handler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	minLevel := h.baseLevel()
+	if h.levelRules != nil {
+		minLevel = min(minLevel, h.levelRules.minLevel())
+	}
+	return lvl >= minLevel
+}
+
+// baseLevel returns the minimum level set by the handler options.
+func (h *Handler) baseLevel() slog.Level {
 	// Copied from slog.commonHandler.enabled
 	minLevel := slog.LevelInfo
-	if h.opts.Level != nil {
+	if h.opts != nil && h.opts.Level != nil {
 		minLevel = h.opts.Level.Level()
 	}
-	return lvl >= minLevel
+	return minLevel
 }
 
 // Handle implements [slog.Handler] interface.
 func (ha *Handler) Handle(ctx context.Context, rec slog.Record) error {
-	buf := ha.pool.Get()
-	defer ha.pool.Put(buf)
-
-	frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+	frame, ok := FrameFromContext(ctx)
+	var entry *frameEntry
+	if !ok {
+		frame, entry = ha.frames.lookup(rec.PC)
+	}
+	if ha.levelRules != nil {
+		minLevel := ha.baseLevel()
+		if leveler, ok := ha.levelRules.leveler(rec.PC, frame.Function); ok {
+			minLevel = leveler.Level()
+		}
+		if rec.Level < minLevel {
+			return nil
+		}
+	}
+	if ha.redactor != nil {
+		rec = ha.redactor.record(rec, ha.groups)
+	}
+	theme := ha.theme
+	if theme == nil {
+		theme = DefaultTheme
+	}
+	start, previous := ha.clock.observe(rec.Time)
 	info := RecordData{
 		Context:        ctx,
 		Record:         rec,
 		PackageName:    ha.packageName,
 		Frame:          frame,
 		HandlerOptions: cloneHandlerOptions(ha.opts),
+		HandlerAttrs:   ha.groupedAttrs,
+		Groups:         ha.groups,
 		Color:          ha.color,
+		ColorDepth:     ha.depthOf(ha.color),
+		Layout:         ha.layout,
+		Wrap:           ha.wrap.resolve(ha.writer),
+		Theme:          theme,
+		Levels:         ha.levels,
+		Start:          start,
+		Previous:       previous,
+		KeyAlignment:   ha.keyAlignment,
 		KeyFieldLength: 0,
-		Buffer:         buf,
+		frameEntry:     entry,
+	}
+	if ha.keyAlignment == KeyAlignFixed {
+		info.KeyFieldLength = ha.keyWidth
+	}
+	if depth := ha.callersDepth(rec.Level); depth > 0 {
+		// Captured here, as writers may run after the logging call returned (see WithAsync).
+		pcs := make([]uintptr, depth)
+		info.Callers = pcs[:runtime.Callers(2, pcs)]
+	}
+	if len(ha.outputs) == 0 {
+		return ha.emit(ha.render(info, ha.writers), ha.writer)
+	}
+
+	// Render once per distinct (color, writers, width) combination.
+	type rendered struct {
+		color   bool
+		writers []EntryWriter
+		width   int
+		buf     *bytes.Buffer
+	}
+	var (
+		renders []rendered
+		errs    []error
+	)
+	defer func() {
+		for _, r := range renders {
+			ha.pool.Put(r.buf)
+		}
+	}()
+	for _, out := range ha.outputs {
+		writers := out.writers
+		if writers == nil {
+			writers = ha.writers
+		}
+		wrap := ha.wrap.resolve(out.writer)
+		width := 0
+		if wrap != nil {
+			width = wrap.Width
+		}
+		index := slices.IndexFunc(renders, func(r rendered) bool {
+			return r.color == out.color && r.width == width && sameWriters(r.writers, writers)
+		})
+		if index == -1 {
+			info.Color = out.color
+			info.ColorDepth = ha.depthOf(out.color)
+			info.Wrap = wrap
+			renders = append(renders, rendered{color: out.color, writers: writers, width: width, buf: ha.render(info, writers)})
+			index = len(renders) - 1
+		}
+		buf := ha.pool.Get()
+		buf.Write(renders[index].buf.Bytes())
+		if err := ha.emit(buf, out.writer); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// callersWriter is implemented by writers that need [RecordData.Callers].
+type callersWriter interface {
+	// callersDepth returns the number of frames to capture for records at level, or 0 if none.
+	callersDepth(level slog.Level) int
+}
+
+// callersDepth returns the number of frames to capture for records at level, as needed by the writers.
+func (ha *Handler) callersDepth(level slog.Level) int {
+	depth := 0
+	collect := func(writers []EntryWriter) {
+		for _, w := range writers {
+			if cw, ok := w.(callersWriter); ok {
+				depth = max(depth, cw.callersDepth(level))
+			}
+		}
+	}
+	collect(ha.writers)
+	for _, out := range ha.outputs {
+		collect(out.writers)
+	}
+	return depth
+}
+
+// sameWriters reports whether a and b are the same writer set, i.e. share the same backing
+// array and length. Writers are not compared by value, as their dynamic type may not be comparable.
+func sameWriters(a, b []EntryWriter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+// depthOf returns the color depth of output with the given color setting.
+func (ha *Handler) depthOf(colored bool) ColorDepth {
+	if !colored {
+		return ColorNone
 	}
-	keyFieldLength := 0
-	for _, w := range ha.writers {
-		if l := w.KeyLen(info); l > keyFieldLength {
-			keyFieldLength = l
+	return max(ha.colorDepth, Color16)
+}
+
+// render runs the writers over info and returns the buffer from the pool they wrote to.
+func (ha *Handler) render(info RecordData, writers []EntryWriter) *bytes.Buffer {
+	buf := ha.pool.Get()
+	info.Buffer = buf
+	if info.KeyAlignment != KeyAlignFixed {
+		keyFieldLength := 0
+		for _, w := range writers {
+			if l := w.KeyLen(info); l > keyFieldLength {
+				keyFieldLength = l
+			}
 		}
+		info.KeyFieldLength = keyFieldLength
 	}
-	info.KeyFieldLength = keyFieldLength
-	for _, w := range ha.writers {
+	for _, w := range writers {
 		w.Write(info)
 	}
+	return buf
+}
+
+// emit writes buf to w and puts buf back to the pool.
+//
+// In asynchronous mode, buf is handed to the background goroutine instead.
+func (ha *Handler) emit(buf *bytes.Buffer, w WriteLocker) error {
 	if buf.Len() == 0 {
+		ha.pool.Put(buf)
+		return nil
+	}
+	if ha.async != nil && ha.async.enqueue(asyncEntry{buf: buf, writer: w, pool: ha.pool}) {
 		return nil
 	}
-	ha.writer.Lock()
-	defer ha.writer.Unlock()
-	_, err := io.Copy(ha.writer, buf)
+	defer ha.pool.Put(buf)
+	w.Lock()
+	defer w.Unlock()
+	_, err := io.Copy(w, buf)
 	return err
 }
 
 // WithAttrs implements [slog.Handler] interface.
 func (ha *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return ha
+	}
 	cloned := ha.Clone()
-	cloned.attrs = append(cloned.attrs, attrs...)
+	grouped := GroupedAttrs{
+		Groups: slices.Clone(cloned.groups),
+		Attrs:  slices.Clone(attrs),
+	}
+	if cloned.redactor != nil {
+		grouped.Attrs = cloned.redactor.attrs(grouped.Groups, grouped.Attrs)
+	}
+	cloned.groupedAttrs = append(cloned.groupedAttrs, grouped)
 	return cloned
 }
 
@@ -99,14 +280,27 @@ func (ha *Handler) WithGroup(name string) slog.Handler {
 // when calling Clone.
 func (handler *Handler) Clone(opts ...Option) *Handler {
 	h := &Handler{
-		attrs:       append([]slog.Attr{}, handler.attrs...), // Must copy values to detach references from original.
-		groups:      append([]string{}, handler.groups...),   // Must copy values to detach references from original.
-		writer:      handler.writer,
-		opts:        handler.opts,
-		pool:        handler.pool,
-		packageName: handler.packageName,
-		color:       handler.color,
-		writers:     handler.writers,
+		groups:       append([]string{}, handler.groups...),             // Must copy values to detach references from original.
+		groupedAttrs: append([]GroupedAttrs{}, handler.groupedAttrs...), // Must copy values to detach references from original.
+		writer:       handler.writer,
+		opts:         handler.opts,
+		pool:         handler.pool,
+		packageName:  handler.packageName,
+		color:        handler.color,
+		colorDepth:   handler.colorDepth,
+		wrap:         handler.wrap,
+		keyAlignment: handler.keyAlignment,
+		keyWidth:     handler.keyWidth,
+		layout:       handler.layout,
+		async:        handler.async,
+		outputs:      handler.outputs,
+		redactor:     handler.redactor,
+		levelRules:   handler.levelRules,
+		theme:        handler.theme,
+		levels:       handler.levels,
+		clock:        handler.clock,
+		frames:       handler.frames,
+		writers:      handler.writers,
 	}
 	for _, opt := range opts {
 		if opt == nil {
@@ -117,6 +311,47 @@ func (handler *Handler) Clone(opts ...Option) *Handler {
 	return h
 }
 
+// Flush blocks until every record queued by the asynchronous mode (see [WithAsync]) has been
+// written to the output, and returns the first write error encountered since the previous
+// call to Flush or [Handler.Close].
+//
+// Flush is a no-op for synchronous handlers.
+func (ha *Handler) Flush() error {
+	if ha.async == nil {
+		return nil
+	}
+	return ha.async.flush()
+}
+
+// Close drains the queue of the asynchronous mode (see [WithAsync]) and stops its background
+// goroutine. Records logged after Close are written synchronously.
+//
+// The queue is shared with handlers derived from this one (e.g. by [Handler.WithAttrs] or [Handler.Clone]),
+// so closing any of them closes it for all.
+//
+// Close is a no-op for synchronous handlers.
+func (ha *Handler) Close() error {
+	if ha.async == nil {
+		return nil
+	}
+	return ha.async.close()
+}
+
+// Dropped returns the number of records dropped by the asynchronous mode because
+// the queue was full. See [WithAsync].
+func (ha *Handler) Dropped() uint64 {
+	if ha.async == nil {
+		return 0
+	}
+	return ha.async.dropped.Load()
+}
+
+// Levels returns the custom level names and styles set by [WithLevelNames] and [WithLevelStyles].
+// Use [Levels.Parse] to map level names back to levels.
+func (ha *Handler) Levels() *Levels {
+	return ha.levels
+}
+
 func cloneHandlerOptions(opts *slog.HandlerOptions) *slog.HandlerOptions {
 	if opts == nil {
 		return nil
@@ -3,11 +3,14 @@ package prettylog
 import (
 	"bytes"
 	"context"
+	"errors"
 	"log/slog"
 	"runtime"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/fatih/color"
 )
 
 func TestHandlerEnabled(t *testing.T) {
@@ -95,7 +98,7 @@ func TestHandlerHandleEmptyBuffer(t *testing.T) {
 
 func TestHandlerWithAttrs(t *testing.T) {
 	handler := &Handler{
-		attrs: []slog.Attr{slog.String("existing", "value")},
+		groupedAttrs: []GroupedAttrs{{Attrs: []slog.Attr{slog.String("existing", "value")}}},
 	}
 
 	newAttrs := []slog.Attr{
@@ -106,24 +109,25 @@ func TestHandlerWithAttrs(t *testing.T) {
 	newHandler := handler.WithAttrs(newAttrs)
 
 	// Original handler should be unchanged
-	if len(handler.attrs) != 1 {
-		t.Errorf("original handler attrs changed, expected 1, got %d", len(handler.attrs))
+	if len(handler.groupedAttrs) != 1 {
+		t.Errorf("original handler attrs changed, expected 1 set, got %d", len(handler.groupedAttrs))
 	}
 
-	// New handler should have combined attributes
+	// New handler should have both attribute sets
 	h := newHandler.(*Handler)
-	if len(h.attrs) != 3 {
-		t.Errorf("new handler should have 3 attrs, got %d", len(h.attrs))
+	if len(h.groupedAttrs) != 2 {
+		t.Fatalf("new handler should have 2 attr sets, got %d", len(h.groupedAttrs))
 	}
 
 	// Verify attributes are correct
-	if h.attrs[0].Key != "existing" || h.attrs[0].Value.String() != "value" {
+	if a := h.groupedAttrs[0].Attrs[0]; a.Key != "existing" || a.Value.String() != "value" {
 		t.Error("existing attribute not preserved correctly")
 	}
-	if h.attrs[1].Key != "key1" || h.attrs[1].Value.String() != "value1" {
+	added := h.groupedAttrs[1].Attrs
+	if len(added) != 2 || added[0].Key != "key1" || added[0].Value.String() != "value1" {
 		t.Error("first new attribute not added correctly")
 	}
-	if h.attrs[2].Key != "key2" || h.attrs[2].Value.String() != "42" {
+	if len(added) != 2 || added[1].Key != "key2" || added[1].Value.String() != "42" {
 		t.Error("second new attribute not added correctly")
 	}
 }
@@ -170,14 +174,14 @@ func TestHandlerClone(t *testing.T) {
 	pool := newLimitedPool(1024)
 	
 	original := &Handler{
-		attrs:       []slog.Attr{slog.String("key", "value")},
-		groups:      []string{"group1"},
-		writer:      WrapWriteLocker(buf),
-		opts:        &slog.HandlerOptions{Level: slog.LevelDebug},
-		color:       true,
-		writers:     []EntryWriter{DefaultLevelWriter},
-		packageName: "testpkg",
-		pool:        pool,
+		groupedAttrs: []GroupedAttrs{{Attrs: []slog.Attr{slog.String("key", "value")}}},
+		groups:       []string{"group1"},
+		writer:       WrapWriteLocker(buf),
+		opts:         &slog.HandlerOptions{Level: slog.LevelDebug},
+		color:        true,
+		writers:      []EntryWriter{DefaultLevelWriter},
+		packageName:  "testpkg",
+		pool:         pool,
 	}
 
 	cloned := original.Clone()
@@ -200,14 +204,14 @@ func TestHandlerClone(t *testing.T) {
 	}
 
 	// Verify deep copies of slices
-	if &cloned.attrs == &original.attrs {
-		t.Error("attrs slice should be deep copied")
+	if &cloned.groupedAttrs == &original.groupedAttrs {
+		t.Error("groupedAttrs slice should be deep copied")
 	}
-	if len(cloned.attrs) != len(original.attrs) {
-		t.Error("attrs content should be copied")
+	if len(cloned.groupedAttrs) != len(original.groupedAttrs) {
+		t.Error("groupedAttrs content should be copied")
 	}
-	if cloned.attrs[0].Key != original.attrs[0].Key {
-		t.Error("attrs values should be copied")
+	if cloned.groupedAttrs[0].Attrs[0].Key != original.groupedAttrs[0].Attrs[0].Key {
+		t.Error("groupedAttrs values should be copied")
 	}
 
 	if &cloned.groups == &original.groups {
@@ -221,10 +225,10 @@ func TestHandlerClone(t *testing.T) {
 	}
 
 	// Modify cloned slices and verify original is unchanged
-	cloned.attrs = append(cloned.attrs, slog.String("new", "attr"))
+	cloned.groupedAttrs = append(cloned.groupedAttrs, GroupedAttrs{Attrs: []slog.Attr{slog.String("new", "attr")}})
 	cloned.groups = append(cloned.groups, "newgroup")
 
-	if len(original.attrs) != 1 {
+	if len(original.groupedAttrs) != 1 {
 		t.Error("original attrs should not be affected by clone modification")
 	}
 	if len(original.groups) != 1 {
@@ -353,4 +357,99 @@ func (tw *testWriterWithKeyLen) KeyLen(info RecordData) int {
 func (tw *testWriterWithKeyLen) Write(info RecordData) {
 	tw.lastKeyFieldLength = info.KeyFieldLength
 	info.Buffer.WriteString(tw.name)
-}
\ No newline at end of file
+}
+func TestHandlerWithOutputs(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	colored := &bytes.Buffer{}
+	plain := &bytes.Buffer{}
+	plain2 := &bytes.Buffer{}
+	custom := &bytes.Buffer{}
+
+	renders := 0
+	counting := NewCommonWriter(func(info RecordData) string {
+		renders++
+		return info.Record.Message
+	}).WithValueColorizer(SimpleColoredStyler)
+
+	handler := New(
+		WithWriters(counting),
+		WithOutputs(
+			Output{Writer: colored, Color: true},
+			Output{Writer: plain, Color: false},
+			Output{Writer: plain2, Color: false},
+			Output{Writer: custom, Color: false, Writers: []EntryWriter{DefaultLogfmtWriter}},
+		),
+	)
+	slog.New(handler).WithGroup("g").Info("message", "key", "value")
+
+	if renders != 2 {
+		t.Errorf("expected 2 renders of the shared writers, got %d", renders)
+	}
+	if !strings.Contains(colored.String(), "\x1b[") {
+		t.Errorf("expected colored output, got %q", colored.String())
+	}
+	if plain.String() != "message" || plain2.String() != "message" {
+		t.Errorf("expected plain outputs, got %q and %q", plain.String(), plain2.String())
+	}
+	if custom.String() != "g.key=value" {
+		t.Errorf("expected custom writers output with group, got %q", custom.String())
+	}
+}
+
+func TestHandlerWithOutputsUncomparableWriters(t *testing.T) {
+	renders := 0
+	counting := writerFunc(func(info RecordData) {
+		renders++
+		info.Buffer.WriteString(info.Record.Message)
+	})
+	first := &bytes.Buffer{}
+	second := &bytes.Buffer{}
+	custom := &bytes.Buffer{}
+	handler := New(
+		WithWriters(counting),
+		WithOutputs(
+			Output{Writer: first},
+			Output{Writer: second},
+			Output{Writer: custom, Writers: []EntryWriter{writerFunc(func(info RecordData) {
+				info.Buffer.WriteString("custom")
+			})}},
+		),
+	)
+	slog.New(handler).Info("message")
+
+	if renders != 1 {
+		t.Errorf("expected outputs sharing the writers to render once, got %d renders", renders)
+	}
+	if first.String() != "message" || second.String() != "message" {
+		t.Errorf("expected shared writers output, got %q and %q", first.String(), second.String())
+	}
+	if custom.String() != "custom" {
+		t.Errorf("expected custom writers output, got %q", custom.String())
+	}
+}
+
+func TestHandlerWithOutputsErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err1 := errors.New("first failed")
+	err2 := errors.New("second failed")
+	handler := New(
+		WithWriters(DefaultMessageWriter),
+		WithOutputs(
+			Output{Writer: &errorWriter{err: err1}},
+			Output{Writer: buf},
+			Output{Writer: &errorWriter{err: err2}},
+		),
+	)
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+	err := handler.Handle(context.Background(), record)
+
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Errorf("expected both errors to be joined, got %v", err)
+	}
+	if buf.String() != "message" {
+		t.Errorf("expected healthy output to be written, got %q", buf.String())
+	}
+}
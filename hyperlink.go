@@ -0,0 +1,141 @@
+package prettylog
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// URL templates for [FileLineWriter.WithHyperlink] and [FunctionWriter.WithHyperlink].
+//
+// Templates may use the following placeholders:
+//   - {abs}: Absolute path of the source file, starting with a slash.
+//   - {line}: Line number.
+//   - {path}: Path of the source file relative to the main module root. Only available for
+//     frames of the main module.
+//   - {commit}: VCS revision the binary was built from, or the main module version if the
+//     revision is not stamped. Only available if the binary was built with module support.
+//   - {func}: Full function name.
+//
+// If a placeholder used by the template is not available, no hyperlink is written.
+const (
+	// FileURLTemplate opens the source file with the default application.
+	FileURLTemplate = "file://{abs}"
+	// VSCodeURLTemplate opens the source file at the line in Visual Studio Code.
+	VSCodeURLTemplate = "vscode://file{abs}:{line}"
+	// IdeaURLTemplate opens the source file at the line in JetBrains IDEs (GoLand, IntelliJ IDEA).
+	IdeaURLTemplate = "idea://open?file={abs}&line={line}"
+)
+
+// RepositoryURLTemplate returns a template linking to the source line in a repository web UI,
+// at the commit the binary was built from.
+//
+// The format of the link follows GitHub and Gitea conventions, e.g.
+// RepositoryURLTemplate("https://github.com/tigorlazuardi/prettylog") links to
+// "https://github.com/tigorlazuardi/prettylog/blob/{commit}/{path}#L{line}".
+func RepositoryURLTemplate(repositoryURL string) string {
+	return strings.TrimSuffix(repositoryURL, "/") + "/blob/{commit}/{path}#L{line}"
+}
+
+// Hyperlink wraps text in an OSC 8 terminal hyperlink to url.
+//
+// Terminals without OSC 8 support display the text only.
+func Hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// HyperlinkURL expands the URL template with the caller information of info.
+// See [FileURLTemplate] for the available placeholders.
+//
+// It returns an empty string if a placeholder used by the template is not available.
+func HyperlinkURL(template string, info RecordData) string {
+	if !hasCaller(info.Frame) {
+		return ""
+	}
+	var (
+		sb   strings.Builder
+		rest = template
+	)
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start == -1 {
+			sb.WriteString(rest)
+			return sb.String()
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end == -1 {
+			sb.WriteString(rest)
+			return sb.String()
+		}
+		end += start
+		sb.WriteString(rest[:start])
+		value, ok := hyperlinkPlaceholder(rest[start+1:end], info)
+		if !ok {
+			return ""
+		}
+		sb.WriteString(value)
+		rest = rest[end+1:]
+	}
+}
+
+func hyperlinkPlaceholder(name string, info RecordData) (string, bool) {
+	switch name {
+	case "abs":
+		if info.Frame.File == "" {
+			return "", false
+		}
+		abs := filepath.ToSlash(info.Frame.File)
+		if !strings.HasPrefix(abs, "/") {
+			// Windows paths, e.g. C:/src/main.go.
+			abs = "/" + abs
+		}
+		return (&url.URL{Path: abs}).EscapedPath(), true
+	case "line":
+		return strconv.Itoa(info.Frame.Line), info.Frame.Line > 0
+	case "path":
+		rel := mainModuleRelativePath(info.Frame.Function, info.Frame.File)
+		return (&url.URL{Path: rel}).EscapedPath(), rel != ""
+	case "commit":
+		commit := buildCommit()
+		return commit, commit != ""
+	case "func":
+		return url.QueryEscape(info.Frame.Function), info.Frame.Function != ""
+	default:
+		return "", false
+	}
+}
+
+// buildCommit returns the VCS revision of the binary, or the main module version.
+func buildCommit() string {
+	bi, ok := readBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range bi.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	return ""
+}
+
+// hyperlinkStyleWriter returns a style writer wrapping what sw writes in a hyperlink built from template.
+func hyperlinkStyleWriter(template string, sw StyleWriter) StyleWriter {
+	return func(info RecordData, buf *bytes.Buffer, s string) {
+		link := HyperlinkURL(template, info)
+		if link == "" {
+			sw(info, buf, s)
+			return
+		}
+		buf.WriteString("\x1b]8;;")
+		buf.WriteString(link)
+		buf.WriteString("\x1b\\")
+		sw(info, buf, s)
+		buf.WriteString("\x1b]8;;\x1b\\")
+	}
+}
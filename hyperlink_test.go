@@ -0,0 +1,122 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func withBuildInfo(t *testing.T, bi *debug.BuildInfo) {
+	t.Helper()
+	original := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return bi, bi != nil }
+	t.Cleanup(func() { readBuildInfo = original })
+}
+
+func TestHyperlinkURL(t *testing.T) {
+	withBuildInfo(t, &debug.BuildInfo{
+		Path:     "github.com/user/repo/cmd/app",
+		Main:     debug.Module{Path: "github.com/user/repo", Version: "(devel)"},
+		Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "0123abc"}},
+	})
+	info := RecordData{Frame: runtime.Frame{
+		Function: "github.com/user/repo/internal/server.(*Server).Serve",
+		File:     "/home/user/src/repo/internal/server/my server.go",
+		Line:     42,
+	}}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"file", FileURLTemplate, "file:///home/user/src/repo/internal/server/my%20server.go"},
+		{"vscode", VSCodeURLTemplate, "vscode://file/home/user/src/repo/internal/server/my%20server.go:42"},
+		{"idea", IdeaURLTemplate, "idea://open?file=/home/user/src/repo/internal/server/my%20server.go&line=42"},
+		{
+			"repository",
+			RepositoryURLTemplate("https://github.com/user/repo/"),
+			"https://github.com/user/repo/blob/0123abc/internal/server/my%20server.go#L42",
+		},
+		{"unknown placeholder", "https://example.com/{unknown}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HyperlinkURL(tt.template, info); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHyperlinkURLMainPackage(t *testing.T) {
+	withBuildInfo(t, &debug.BuildInfo{
+		Path: "github.com/user/repo/cmd/app",
+		Main: debug.Module{Path: "github.com/user/repo", Version: "v1.2.3"},
+	})
+	info := RecordData{Frame: runtime.Frame{Function: "main.main", File: "/build/cmd/app/main.go", Line: 7}}
+
+	expected := "https://github.com/user/repo/blob/v1.2.3/cmd/app/main.go#L7"
+	if got := HyperlinkURL(RepositoryURLTemplate("https://github.com/user/repo"), info); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	dependency := RecordData{Frame: runtime.Frame{Function: "github.com/other/lib.Do", File: "/mod/lib.go", Line: 1}}
+	if got := HyperlinkURL(RepositoryURLTemplate("https://github.com/user/repo"), dependency); got != "" {
+		t.Errorf("expected no link for frames outside of the main module, got %q", got)
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	tests := map[string]string{
+		"main.main": "main",
+		"github.com/user/repo/pkg.(*Type).Method": "github.com/user/repo/pkg",
+		"github.com/user/repo.Func.func1":         "github.com/user/repo",
+		"gopkg.in/yaml%2ev3.Unmarshal":            "gopkg.in/yaml.v3",
+	}
+	for function, expected := range tests {
+		if got := packagePath(function); got != expected {
+			t.Errorf("packagePath(%q): expected %q, got %q", function, expected, got)
+		}
+	}
+}
+
+func TestWriterWithHyperlink(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	pc, file, _, _ := runtime.Caller(0)
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "message", pc)
+	writers := WithWriters(
+		NewFileLineWriter().WithLongFormat().WithHyperlink(VSCodeURLTemplate),
+		NewFunctionWriter().WithHyperlink(FileURLTemplate),
+	)
+
+	buf := &bytes.Buffer{}
+	if err := New(WithOutput(buf), WithColor(true), writers).Handle(t.Context(), record); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	link := "\x1b]8;;vscode://file" + file + ":"
+	if !strings.Contains(got, link) {
+		t.Errorf("expected output to contain hyperlink %q, got %q", link, got)
+	}
+	if !strings.Contains(StripANSI(got), "File "+file+":") {
+		t.Errorf("expected hyperlink to be invisible, got %q", got)
+	}
+
+	buf.Reset()
+	if err := New(WithOutput(buf), WithColor(false), writers).Handle(t.Context(), record); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\x1b]8;;") {
+		t.Errorf("expected no hyperlink without color, got %q", buf.String())
+	}
+}
@@ -0,0 +1,684 @@
+package prettylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tidwall/pretty"
+)
+
+// jsonEncoder writes the attributes of a record as indented, optionally colored JSON in a single pass.
+//
+// The output is identical to serializing the record with [slog.JSONHandler] (without the built-in keys)
+// and formatting the result with [pretty.PrettyOptions] and [pretty.Color], without building the
+// intermediate JSON document.
+type jsonEncoder struct {
+	buf     []byte
+	prefix  string
+	indent  string
+	width   int
+	style   *pretty.Style
+	replace replaceAttrFunc
+	groups  []string
+
+	scratch []byte
+	marshal *bytes.Buffer
+	json    *json.Encoder
+}
+
+var jsonEncoderPool = sync.Pool{
+	New: func() any {
+		e := &jsonEncoder{marshal: &bytes.Buffer{}}
+		e.json = json.NewEncoder(e.marshal)
+		e.json.SetEscapeHTML(false)
+		return e
+	},
+}
+
+// appendJSONAttrs appends the attributes of info to dst as a JSON object formatted by opts,
+// and colored by style if style is not nil.
+//
+// It reports false, and returns dst unchanged, if there are no attributes to write.
+func appendJSONAttrs(dst []byte, info RecordData, opts *pretty.Options, style *pretty.Style) ([]byte, bool) {
+	if opts == nil {
+		opts = pretty.DefaultOptions
+	}
+	e := jsonEncoderPool.Get().(*jsonEncoder)
+	defer e.release()
+
+	e.buf = dst
+	e.prefix, e.indent, e.width = opts.Prefix, opts.Indent, opts.Width
+	e.style = style
+	if info.HandlerOptions != nil {
+		e.replace = info.HandlerOptions.ReplaceAttr
+	}
+	start := len(e.buf)
+	e.rawString(e.prefix)
+	e.punct('{')
+	if e.handlerAttrs(info, 0, 0) == 0 {
+		return dst[:start], false
+	}
+	e.newline(0)
+	e.punct('}')
+	e.rawByte('\n')
+	return e.buf, true
+}
+
+func (e *jsonEncoder) release() {
+	const maxBufferSize = 16 << 10
+	if e.marshal.Cap() > maxBufferSize || cap(e.scratch) > maxBufferSize {
+		return
+	}
+	e.buf = nil
+	e.style = nil
+	e.replace = nil
+	e.groups = e.groups[:0]
+	e.marshal.Reset()
+	jsonEncoderPool.Put(e)
+}
+
+// handlerAttrs writes the attributes collected by [Handler.WithAttrs] under the first depth groups
+// of info.Groups, followed by the group opened at depth or, at the deepest level, the record attributes.
+//
+// n is the number of members already written to the current object. It returns the new number of members.
+func (e *jsonEncoder) handlerAttrs(info RecordData, depth, n int) int {
+	tabs := depth + 1
+	for _, ga := range info.HandlerAttrs {
+		if len(ga.Groups) != depth {
+			continue
+		}
+		for _, a := range ga.Attrs {
+			if e.attr(a, tabs, n > 0) {
+				n++
+			}
+		}
+	}
+	if depth == len(info.Groups) {
+		info.Record.Attrs(func(a slog.Attr) bool {
+			if e.attr(a, tabs, n > 0) {
+				n++
+			}
+			return true
+		})
+		return n
+	}
+
+	// Groups opened by Handler.WithGroup are omitted if nothing is written in them.
+	pos := len(e.buf)
+	name := info.Groups[depth]
+	e.member(e.key(name), tabs, n > 0)
+	e.punct('{')
+	e.groups = append(e.groups, name)
+	written := e.handlerAttrs(info, depth+1, 0)
+	e.groups = e.groups[:len(e.groups)-1]
+	if written == 0 {
+		e.buf = e.buf[:pos]
+		return n
+	}
+	e.newline(tabs)
+	e.punct('}')
+	return n + 1
+}
+
+// attr writes a as a member of the current object, following the rules of [slog.JSONHandler]:
+// values are resolved, ReplaceAttr is applied, empty attributes and groups are elided,
+// and groups with empty keys are inlined. It reports whether anything was written.
+func (e *jsonEncoder) attr(a slog.Attr, tabs int, sep bool) bool {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		if len(e.groups) == 0 && isBuiltinKey(a.Key) {
+			return false
+		}
+		if e.replace != nil {
+			a = e.replace(e.groups, a)
+			a.Value = a.Value.Resolve()
+		}
+	}
+	if a.Equal(slog.Attr{}) {
+		return false
+	}
+	if a.Value.Kind() == slog.KindAny {
+		if src, ok := a.Value.Any().(*slog.Source); ok {
+			if src == nil || (src.Function == "" && src.File == "" && src.Line == 0) {
+				return false
+			}
+			a.Value = sourceGroup(src)
+		}
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		key := e.key(a.Key)
+		e.member(key, tabs, sep)
+		e.value(a.Value, tabs, e.column(tabs)+len(key)+2)
+		return true
+	}
+
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return false
+	}
+	pos := len(e.buf)
+	if a.Key == "" {
+		n := 0
+		for _, ga := range attrs {
+			if e.attr(ga, tabs, sep || n > 0) {
+				n++
+			}
+		}
+		return n > 0
+	}
+	e.member(e.key(a.Key), tabs, sep)
+	e.punct('{')
+	e.groups = append(e.groups, a.Key)
+	n := 0
+	for _, ga := range attrs {
+		if e.attr(ga, tabs+1, n > 0) {
+			n++
+		}
+	}
+	e.groups = e.groups[:len(e.groups)-1]
+	if n == 0 {
+		e.buf = e.buf[:pos]
+		return false
+	}
+	e.newline(tabs)
+	e.punct('}')
+	return true
+}
+
+func sourceGroup(src *slog.Source) slog.Value {
+	var attrs []slog.Attr
+	if src.Function != "" {
+		attrs = append(attrs, slog.String("function", src.Function))
+	}
+	if src.File != "" {
+		attrs = append(attrs, slog.String("file", src.File))
+	}
+	if src.Line != 0 {
+		attrs = append(attrs, slog.Int("line", src.Line))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// key returns the quoted and escaped key. The result is only valid until the next call to key.
+func (e *jsonEncoder) key(key string) []byte {
+	e.scratch = append(e.scratch[:0], '"')
+	e.scratch = appendEscapedJSONString(e.scratch, key)
+	e.scratch = append(e.scratch, '"')
+	return e.scratch
+}
+
+// member starts a new member of an object with the quoted key, preceded by a separator if sep is true.
+func (e *jsonEncoder) member(key []byte, tabs int, sep bool) {
+	if sep {
+		e.punct(',')
+	}
+	e.newline(tabs)
+	e.quoted(key, e.keyStyle())
+	e.punct(':')
+	e.rawByte(' ')
+}
+
+// value writes a resolved, non-group value. col is the width of the current line so far,
+// including the line break, and is used to decide if arrays fit on a single line.
+func (e *jsonEncoder) value(v slog.Value, tabs, col int) {
+	pos := len(e.buf)
+	defer func() {
+		if r := recover(); r != nil {
+			e.buf = e.buf[:pos]
+			if v.Kind() == slog.KindAny {
+				if rv := reflect.ValueOf(v.Any()); rv.Kind() == reflect.Pointer && rv.IsNil() {
+					e.string("<nil>")
+					return
+				}
+			}
+			e.string(fmt.Sprintf("!PANIC: %v", r))
+		}
+	}()
+
+	switch v.Kind() {
+	case slog.KindString:
+		e.string(v.String())
+	case slog.KindInt64:
+		e.token(strconv.AppendInt(e.scratch[:0], v.Int64(), 10), e.numberStyle())
+	case slog.KindUint64:
+		e.token(strconv.AppendUint(e.scratch[:0], v.Uint64(), 10), e.numberStyle())
+	case slog.KindFloat64:
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			// Let encoding/json report the error, like slog.JSONHandler does.
+			e.marshalJSON(f, tabs, col)
+			return
+		}
+		e.token(appendJSONFloat(e.scratch[:0], f), e.numberStyle())
+	case slog.KindBool:
+		if v.Bool() {
+			e.token([]byte("true"), e.trueStyle())
+		} else {
+			e.token([]byte("false"), e.falseStyle())
+		}
+	case slog.KindDuration:
+		e.token(strconv.AppendInt(e.scratch[:0], int64(v.Duration()), 10), e.numberStyle())
+	case slog.KindTime:
+		t := v.Time()
+		if y := t.Year(); y < 0 || y >= 10000 {
+			e.error(errors.New("time.Time year outside of range [0,9999]"))
+			return
+		}
+		e.scratch = append(e.scratch[:0], '"')
+		e.scratch = t.AppendFormat(e.scratch, time.RFC3339Nano)
+		e.scratch = append(e.scratch, '"')
+		e.quoted(e.scratch, e.stringStyle())
+	default:
+		a := v.Any()
+		_, jm := a.(json.Marshaler)
+		if err, ok := a.(error); ok && !jm {
+			e.string(err.Error())
+			return
+		}
+		e.marshalJSON(a, tabs, col)
+	}
+}
+
+func (e *jsonEncoder) error(err error) {
+	e.string("!ERROR:" + err.Error())
+}
+
+// marshalJSON writes v encoded by encoding/json, reformatted the way [pretty.PrettyOptions] would.
+func (e *jsonEncoder) marshalJSON(v any, tabs, col int) {
+	e.marshal.Reset()
+	if err := e.json.Encode(v); err != nil {
+		e.error(err)
+		return
+	}
+	raw := e.marshal.Bytes()
+	e.rawValue(raw[:len(raw)-1], 0, tabs, col) // remove final newline
+}
+
+// rawValue writes the JSON value starting at raw[i] and returns the index after it.
+func (e *jsonEncoder) rawValue(raw []byte, i, tabs, col int) int {
+	for i < len(raw) && raw[i] <= ' ' {
+		i++
+	}
+	if i >= len(raw) {
+		return i
+	}
+	switch c := raw[i]; c {
+	case '"':
+		end := rawStringEnd(raw, i)
+		e.quoted(raw[i:end], e.stringStyle())
+		return end
+	case '{':
+		return e.rawObject(raw, i, tabs)
+	case '[':
+		return e.rawArray(raw, i, tabs, col)
+	default:
+		end := i + 1
+		for end < len(raw) && raw[end] > ' ' && raw[end] != ',' && raw[end] != ':' && raw[end] != ']' && raw[end] != '}' {
+			end++
+		}
+		style := e.numberStyle()
+		switch c {
+		case 't':
+			style = e.trueStyle()
+		case 'f':
+			style = e.falseStyle()
+		case 'n':
+			style = e.nullStyle()
+		}
+		e.token(raw[i:end], style)
+		return end
+	}
+}
+
+func (e *jsonEncoder) rawObject(raw []byte, i, tabs int) int {
+	e.punct('{')
+	i++
+	n := 0
+	for i < len(raw) {
+		c := raw[i]
+		if c == '}' {
+			i++
+			break
+		}
+		if c != '"' {
+			i++
+			continue
+		}
+		end := rawStringEnd(raw, i)
+		key := raw[i:end]
+		e.member(key, tabs+1, n > 0)
+		i = end
+		for i < len(raw) && (raw[i] <= ' ' || raw[i] == ':') {
+			i++
+		}
+		i = e.rawValue(raw, i, tabs+1, e.column(tabs+1)+len(key)+2)
+		n++
+	}
+	if n > 0 {
+		e.newline(tabs)
+	}
+	e.punct('}')
+	return i
+}
+
+func (e *jsonEncoder) rawArray(raw []byte, i, tabs, col int) int {
+	if e.width > 0 {
+		// Like pretty, write the array on a single line if it fits within the width
+		// and contains no objects.
+		if limit := e.width - col; limit > 3 {
+			start, style := len(e.buf), e.style
+			e.style = nil
+			end, ok := e.compactArray(raw, i)
+			e.style = style
+			if ok && len(e.buf)-start <= limit {
+				if style == nil {
+					return end
+				}
+				e.buf = e.buf[:start]
+				end, _ = e.compactArray(raw, i)
+				return end
+			}
+			e.buf = e.buf[:start]
+		}
+	}
+
+	e.punct('[')
+	i++
+	n := 0
+	for i < len(raw) {
+		c := raw[i]
+		if c == ']' {
+			i++
+			break
+		}
+		if c <= ' ' || c == ',' {
+			i++
+			continue
+		}
+		if n > 0 {
+			e.rawByte(',')
+		}
+		e.newline(tabs + 1)
+		i = e.rawValue(raw, i, tabs+1, e.column(tabs+1))
+		n++
+	}
+	if n > 0 {
+		e.newline(tabs)
+	}
+	e.punct(']')
+	return i
+}
+
+// compactArray writes the array starting at raw[i] on a single line.
+// It reports false if the array contains an object.
+func (e *jsonEncoder) compactArray(raw []byte, i int) (int, bool) {
+	e.punct('[')
+	i++
+	n := 0
+	for i < len(raw) {
+		c := raw[i]
+		if c == ']' {
+			i++
+			break
+		}
+		if c <= ' ' || c == ',' {
+			i++
+			continue
+		}
+		if n > 0 {
+			e.rawByte(',')
+			e.rawByte(' ')
+		}
+		switch c {
+		case '{':
+			return i, false
+		case '[':
+			var ok bool
+			if i, ok = e.compactArray(raw, i); !ok {
+				return i, false
+			}
+		default:
+			i = e.rawValue(raw, i, 0, 0)
+		}
+		n++
+	}
+	e.punct(']')
+	return i, true
+}
+
+// rawStringEnd returns the index after the JSON string starting at raw[i].
+func rawStringEnd(raw []byte, i int) int {
+	for i++; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(raw)
+}
+
+// column returns the width of a line indented by tabs, including the preceding line break.
+func (e *jsonEncoder) column(tabs int) int {
+	return 1 + len(e.prefix) + tabs*len(e.indent)
+}
+
+func (e *jsonEncoder) newline(tabs int) {
+	e.rawByte('\n')
+	e.rawString(e.prefix)
+	for range tabs {
+		e.rawString(e.indent)
+	}
+}
+
+// string writes s as a quoted and escaped JSON string.
+func (e *jsonEncoder) string(s string) {
+	if e.style == nil {
+		e.buf = append(e.buf, '"')
+		e.buf = appendEscapedJSONString(e.buf, s)
+		e.buf = append(e.buf, '"')
+		return
+	}
+	e.scratch = append(e.scratch[:0], '"')
+	e.scratch = appendEscapedJSONString(e.scratch, s)
+	e.scratch = append(e.scratch, '"')
+	e.quoted(e.scratch, e.stringStyle())
+}
+
+// quoted writes the quoted JSON string s. Escape sequences are styled the way [pretty.Color] does.
+func (e *jsonEncoder) quoted(s []byte, style [2]string) {
+	if e.style == nil {
+		e.buf = append(e.buf, s...)
+		return
+	}
+	e.buf = append(e.buf, style[0]...)
+	esc, remaining := false, 0
+	for i, c := range s {
+		switch {
+		case c == '\\':
+			// Like pretty, a backslash always starts a new escape sequence, even if it is escaped.
+			e.buf = append(e.buf, style[1]...)
+			e.buf = append(e.buf, e.style.Escape[0]...)
+			e.rawByte(c)
+			esc, remaining = true, 1
+			if i+1 < len(s) && s[i+1] == 'u' {
+				remaining = 5
+			}
+		case esc:
+			e.rawByte(c)
+			if remaining--; remaining == 0 {
+				esc = false
+				e.buf = append(e.buf, e.style.Escape[1]...)
+				e.buf = append(e.buf, style[0]...)
+			}
+		default:
+			e.rawByte(c)
+		}
+	}
+	if esc {
+		e.buf = append(e.buf, e.style.Escape[1]...)
+	} else {
+		e.buf = append(e.buf, style[1]...)
+	}
+}
+
+// token writes an unquoted JSON value like a number or a boolean.
+func (e *jsonEncoder) token(b []byte, style [2]string) {
+	if e.style == nil {
+		e.buf = append(e.buf, b...)
+		return
+	}
+	e.buf = append(e.buf, style[0]...)
+	for _, c := range b {
+		e.rawByte(c)
+	}
+	e.buf = append(e.buf, style[1]...)
+}
+
+// punct writes a bracket, or a colon or comma of an object.
+func (e *jsonEncoder) punct(c byte) {
+	if e.style == nil {
+		e.buf = append(e.buf, c)
+		return
+	}
+	e.buf = append(e.buf, e.style.Brackets[0]...)
+	e.rawByte(c)
+	e.buf = append(e.buf, e.style.Brackets[1]...)
+}
+
+func (e *jsonEncoder) rawByte(c byte) {
+	if e.style != nil && e.style.Append != nil {
+		e.buf = e.style.Append(e.buf, c)
+		return
+	}
+	e.buf = append(e.buf, c)
+}
+
+func (e *jsonEncoder) rawString(s string) {
+	for i := 0; i < len(s); i++ {
+		e.rawByte(s[i])
+	}
+}
+
+func (e *jsonEncoder) keyStyle() [2]string {
+	if e.style == nil {
+		return [2]string{}
+	}
+	return e.style.Key
+}
+
+func (e *jsonEncoder) stringStyle() [2]string {
+	if e.style == nil {
+		return [2]string{}
+	}
+	return e.style.String
+}
+
+func (e *jsonEncoder) numberStyle() [2]string {
+	if e.style == nil {
+		return [2]string{}
+	}
+	return e.style.Number
+}
+
+func (e *jsonEncoder) trueStyle() [2]string {
+	if e.style == nil {
+		return [2]string{}
+	}
+	return e.style.True
+}
+
+func (e *jsonEncoder) falseStyle() [2]string {
+	if e.style == nil {
+		return [2]string{}
+	}
+	return e.style.False
+}
+
+func (e *jsonEncoder) nullStyle() [2]string {
+	if e.style == nil {
+		return [2]string{}
+	}
+	return e.style.Null
+}
+
+// appendJSONFloat appends f the way encoding/json encodes float64 values.
+func appendJSONFloat(b []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+const jsonHex = "0123456789abcdef"
+
+// appendEscapedJSONString appends s escaped the way [slog.JSONHandler] escapes strings.
+func appendEscapedJSONString(buf []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= ' ' && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\')
+			switch b {
+			case '\\', '"':
+				buf = append(buf, b)
+			case '\n':
+				buf = append(buf, 'n')
+			case '\r':
+				buf = append(buf, 'r')
+			case '\t':
+				buf = append(buf, 't')
+			default:
+				// This encodes bytes < 0x20 except for \t, \n and \r.
+				buf = append(buf, 'u', '0', '0', jsonHex[b>>4], jsonHex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, string(utf8.RuneError)...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 is LINE SEPARATOR and U+2029 is PARAGRAPH SEPARATOR.
+		// slog escapes them for JSONP, so do we.
+		if c == '\u2028' || c == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\u202`...)
+			buf = append(buf, jsonHex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(buf, s[start:]...)
+}
@@ -0,0 +1,133 @@
+package prettylog
+
+// Layout determines how entries written by [CommonWriter]s are arranged.
+//
+// The layout is available to writers through [RecordData.Layout].
+type Layout int
+
+const (
+	// ColumnLayout puts keyed entries on their own lines, with keys aligned
+	// in a column. This is the layout used by [DefaultWriters].
+	ColumnLayout Layout = iota
+	// CompactLayout puts all entries on a single line, separated by spaces.
+	// Keyed entries are rendered inline as `key=value`.
+	//
+	// Example output:
+	//
+	//	15:04:05 INFO message key=val (pkg.Func file.go:12)
+	CompactLayout
+)
+
+// String implements [fmt.Stringer] interface.
+func (l Layout) String() string {
+	switch l {
+	case ColumnLayout:
+		return "column"
+	case CompactLayout:
+		return "compact"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyAlignment is the alignment of keys in the key column of [ColumnLayout].
+//
+// The alignment is available to writers through [RecordData.KeyAlignment].
+type KeyAlignment int
+
+const (
+	// KeyAlignLeft left-aligns keys and pads them to the widest key. This is the default.
+	//
+	//	level   INFO
+	//	message hello
+	KeyAlignLeft KeyAlignment = iota
+	// KeyAlignRight right-aligns keys to the widest key.
+	//
+	//	  level INFO
+	//	message hello
+	KeyAlignRight
+	// KeyAlignFixed left-aligns keys in a column of fixed width set by [WithKeyWidth].
+	// Keys wider than the column are truncated with an ellipsis, so values always start at
+	// the same column regardless of the writers in use.
+	KeyAlignFixed
+)
+
+// String implements [fmt.Stringer] interface.
+func (a KeyAlignment) String() string {
+	switch a {
+	case KeyAlignLeft:
+		return "left"
+	case KeyAlignRight:
+		return "right"
+	case KeyAlignFixed:
+		return "fixed"
+	default:
+		return "unknown"
+	}
+}
+
+// CompactWriters is the set of entry writers used by [CompactLayout].
+// It includes writers for time, level, message, logfmt attributes and source,
+// and adds a new line at the end.
+var CompactWriters = [...]EntryWriter{
+	CompactTimeWriter,
+	CompactLevelWriter,
+	DefaultMessageWriter,
+	CompactLogfmtWriter,
+	CompactSourceWriter,
+	CompactNewLineWriter,
+}
+
+// CompactTimeWriter is the entry writer for timestamps in [CompactLayout].
+// It uses time-only format without key.
+var CompactTimeWriter = &TimeWriter{
+	CommonWriter: NewCommonWriter(TimeOnlyTimeFormat).WithValueStyleWriter(TimeStyleWriter),
+}
+
+// CompactLevelWriter is the entry writer for log levels in [CompactLayout].
+// It displays the log level with bold colored styling.
+var CompactLevelWriter = NewCommonWriter(DefaultLevelValuer).
+	WithValueStyleWriter(BoldColoredStyleWriter)
+
+// CompactLogfmtWriter is the entry writer for log attributes in [CompactLayout].
+// It writes the attributes inline as logfmt.
+var CompactLogfmtWriter = NewLogfmtWriter().WithInline(true)
+
+// CompactSourceWriter is the entry writer for caller information in [CompactLayout].
+// It writes the function and the file/line in parentheses, e.g. `(pkg.Func file.go:12)`.
+var CompactSourceWriter = NewCommonWriter(CompactSourceFormat).WithValueStyleWriter(SourceStyleWriter)
+
+// CompactNewLineWriter adds a new line at the end of the entry without
+// leaving a trailing space.
+var CompactNewLineWriter = NewCommonWriter(AddNewLineFormat).WithPrefix(NoPrefix)
+
+// CompactSourceFormat returns the function name and file/line of the caller
+// in parentheses, using [ShortFunctionFormat] and [ShortFileLineFormat].
+//
+// Returns an empty string if caller information is not available.
+func CompactSourceFormat(info RecordData) string {
+	if !hasCaller(info.Frame) {
+		return ""
+	}
+	return cachedFrameFormat(info, formatCompactSource, compactSource)
+}
+
+func compactSource(info RecordData) string {
+	return "(" + ShortFunctionFormat(info) + " " + ShortFileLineFormat(info) + ")"
+}
+
+// CompactPrefix returns the prefix between log entry components in [CompactLayout].
+// It returns:
+//   - Empty string if buffer is empty
+//   - Space for subsequent entries
+func CompactPrefix(info RecordData, this *CommonWriter) string {
+	if info.Buffer.Len() == 0 {
+		return ""
+	}
+	return " "
+}
+
+// NoPrefix always returns an empty string.
+func NoPrefix(info RecordData, this *CommonWriter) string {
+	return ""
+}
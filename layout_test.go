@@ -0,0 +1,62 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+func TestCompactLayout(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithPackageName("github.com/tigorlazuardi/prettylog"),
+		WithLayout(CompactLayout),
+	)
+	slog.New(handler).Info("hello world", "key", "val")
+
+	pattern := regexp.MustCompile(`^\d{2}:\d{2}:\d{2} INFO hello world key=val \(prettylog\.TestCompactLayout layout_test\.go:\d+\)\n$`)
+	if !pattern.MatchString(buf.String()) {
+		t.Errorf("unexpected compact output: %q", buf.String())
+	}
+}
+
+func TestCompactLayoutInlineKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithLayout(CompactLayout),
+		WithWriters(
+			DefaultMessageWriter,
+			NewCommonWriter(Static("abc")).WithStaticKey("RequestID"),
+			NewCommonWriter(Static("x")).WithStaticKey("K"),
+		),
+	)
+	slog.New(handler).Info("hello")
+
+	expected := "hello RequestID=abc K=x"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestWithLayout(t *testing.T) {
+	handler := New(WithLayout(CompactLayout))
+	if handler.layout != CompactLayout {
+		t.Errorf("expected compact layout, got %s", handler.layout)
+	}
+	if len(handler.writers) != len(CompactWriters) {
+		t.Errorf("expected %d writers, got %d", len(CompactWriters), len(handler.writers))
+	}
+
+	handler = handler.Clone(WithLayout(ColumnLayout))
+	if handler.layout != ColumnLayout {
+		t.Errorf("expected column layout, got %s", handler.layout)
+	}
+	if len(handler.writers) != len(DefaultWriters) {
+		t.Errorf("expected %d writers, got %d", len(DefaultWriters), len(handler.writers))
+	}
+}
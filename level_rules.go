@@ -0,0 +1,89 @@
+package prettylog
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// levelRules holds the minimum levels set by [WithLevelRules].
+//
+// Matching a function name against the rules is cached per program counter,
+// so repeated log sites cost a single map lookup.
+type levelRules struct {
+	rules []levelRule // sorted by prefix length, longest first.
+	cache sync.Map    // map[uintptr]int, index of the matching rule or -1.
+}
+
+type levelRule struct {
+	prefix string
+	level  slog.Leveler
+}
+
+func newLevelRules(rules map[string]slog.Leveler) *levelRules {
+	lr := &levelRules{}
+	for prefix, level := range rules {
+		if level == nil {
+			continue
+		}
+		lr.rules = append(lr.rules, levelRule{prefix: prefix, level: level})
+	}
+	sort.Slice(lr.rules, func(i, j int) bool {
+		if len(lr.rules[i].prefix) != len(lr.rules[j].prefix) {
+			return len(lr.rules[i].prefix) > len(lr.rules[j].prefix)
+		}
+		return lr.rules[i].prefix < lr.rules[j].prefix
+	})
+	return lr
+}
+
+// minLevel returns the lowest level of all rules.
+func (lr *levelRules) minLevel() slog.Level {
+	minLevel := slog.Level(0)
+	for i, rule := range lr.rules {
+		if l := rule.level.Level(); i == 0 || l < minLevel {
+			minLevel = l
+		}
+	}
+	return minLevel
+}
+
+// leveler returns the level of the rule with the longest prefix matching function.
+// The result is cached by pc if pc is not zero.
+func (lr *levelRules) leveler(pc uintptr, function string) (slog.Leveler, bool) {
+	if pc != 0 {
+		if index, ok := lr.cache.Load(pc); ok {
+			return lr.at(index.(int))
+		}
+	}
+	index := -1
+	for i, rule := range lr.rules {
+		if matchFunctionPrefix(function, rule.prefix) {
+			index = i
+			break
+		}
+	}
+	if pc != 0 {
+		lr.cache.Store(pc, index)
+	}
+	return lr.at(index)
+}
+
+func (lr *levelRules) at(index int) (slog.Leveler, bool) {
+	if index < 0 {
+		return nil, false
+	}
+	return lr.rules[index].level, true
+}
+
+// matchFunctionPrefix reports whether the fully qualified function name starts with prefix
+// at a package or symbol boundary, so "github.com/acme/db" matches "github.com/acme/db.Open"
+// and "github.com/acme/db/pool.New", but not "github.com/acme/dbx.Open".
+func matchFunctionPrefix(function, prefix string) bool {
+	rest, ok := strings.CutPrefix(function, prefix)
+	if !ok {
+		return false
+	}
+	return rest == "" || rest[0] == '.' || rest[0] == '/' || strings.HasSuffix(prefix, ".") || strings.HasSuffix(prefix, "/")
+}
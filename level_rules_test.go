@@ -0,0 +1,102 @@
+package prettylog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func logFromNoisySubsystem(logger *slog.Logger, msg string) {
+	logger.Debug(msg)
+}
+
+func logFromQuietSubsystem(logger *slog.Logger, msg string) {
+	logger.Info(msg)
+}
+
+func TestWithLevelRules(t *testing.T) {
+	buf := &bytes.Buffer{}
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(slog.LevelDebug)
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(DefaultMessageWriter, DefaultNewLineWriter),
+		WithLevel(slog.LevelInfo),
+		WithLevelRules(map[string]slog.Leveler{
+			"github.com/tigorlazuardi/prettylog.logFromNoisySubsystem": levelVar,
+			"github.com/tigorlazuardi/prettylog.logFromQuietSubsystem": slog.LevelWarn,
+		}),
+	)
+	logger := slog.New(handler)
+
+	if !handler.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected debug to be enabled because of the level rules")
+	}
+
+	logger.Debug("debug from test")
+	logFromNoisySubsystem(logger, "debug from noisy")
+	logFromQuietSubsystem(logger, "info from quiet")
+	logger.Info("info from test")
+
+	levelVar.Set(slog.LevelInfo)
+	logFromNoisySubsystem(logger, "debug from noisy after change")
+
+	got := buf.String()
+	for _, want := range []string{"debug from noisy", "info from test"} {
+		if !strings.Contains(got, want+" \n") {
+			t.Errorf("expected %q to be logged, got:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"debug from test", "info from quiet", "after change"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected %q to be filtered, got:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestMatchFunctionPrefix(t *testing.T) {
+	tests := []struct {
+		function string
+		prefix   string
+		expected bool
+	}{
+		{"github.com/acme/db.Open", "github.com/acme/db", true},
+		{"github.com/acme/db.(*Conn).Query", "github.com/acme/db", true},
+		{"github.com/acme/db/pool.New", "github.com/acme/db", true},
+		{"github.com/acme/dbx.Open", "github.com/acme/db", false},
+		{"github.com/acme/db.(*Conn).Query", "github.com/acme/db.(*Conn)", true},
+		{"github.com/acme/db.Open", "github.com/acme/db.", true},
+		{"github.com/acme/http.Serve", "github.com/acme/db", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchFunctionPrefix(tt.function, tt.prefix); got != tt.expected {
+			t.Errorf("matchFunctionPrefix(%q, %q): expected %v, got %v", tt.function, tt.prefix, tt.expected, got)
+		}
+	}
+}
+
+func TestLevelRulesLongestPrefixWins(t *testing.T) {
+	lr := newLevelRules(map[string]slog.Leveler{
+		"github.com/acme":    slog.LevelError,
+		"github.com/acme/db": slog.LevelDebug,
+	})
+	leveler, ok := lr.leveler(1, "github.com/acme/db.Open")
+	if !ok || leveler.Level() != slog.LevelDebug {
+		t.Errorf("expected debug level, got %v", leveler)
+	}
+	// Cached by PC.
+	leveler, ok = lr.leveler(1, "")
+	if !ok || leveler.Level() != slog.LevelDebug {
+		t.Errorf("expected cached debug level, got %v", leveler)
+	}
+	if _, ok := lr.leveler(2, "github.com/other.Func"); ok {
+		t.Error("expected no matching rule")
+	}
+	if lr.minLevel() != slog.LevelDebug {
+		t.Errorf("expected min level debug, got %v", lr.minLevel())
+	}
+}
@@ -0,0 +1,166 @@
+package prettylog
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Levels maps non-standard [slog.Level] values to display names and styles.
+//
+// Set them with [WithLevelNames] and [WithLevelStyles]. They are available to custom
+// formatters and stylers through [RecordData.Levels].
+//
+// A nil *Levels is valid and behaves like the standard slog levels.
+type Levels struct {
+	names  map[slog.Level]string
+	styles map[slog.Level]LevelStyle
+}
+
+var standardLevels = [...]slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// Name returns the display name of the level.
+//
+// Like [slog.Level.String], levels between named levels are rendered relative to the
+// closest named level below them, e.g. with TRACE registered as -8, level -6 is "TRACE+2".
+func (l *Levels) Name(lvl slog.Level) string {
+	if l == nil || len(l.names) == 0 {
+		return lvl.String()
+	}
+	if name, ok := l.names[lvl]; ok {
+		return name
+	}
+
+	// Find the closest named (custom or standard) level below lvl.
+	var (
+		base  slog.Level
+		name  string
+		found bool
+	)
+	consider := func(candidate slog.Level, candidateName string) {
+		if candidate <= lvl && (!found || candidate > base) {
+			base, name, found = candidate, candidateName, true
+		}
+	}
+	for _, std := range standardLevels {
+		if custom, ok := l.names[std]; ok {
+			consider(std, custom)
+		} else {
+			consider(std, std.String())
+		}
+	}
+	for custom, customName := range l.names {
+		consider(custom, customName)
+	}
+	if !found {
+		// Below every named level, slog uses DEBUG as base with a negative offset.
+		base, name = slog.LevelDebug, l.Name(slog.LevelDebug)
+		for custom, customName := range l.names {
+			if custom < base {
+				base, name = custom, customName
+			}
+		}
+	}
+	if lvl == base {
+		return name
+	}
+	return name + signedOffset(int(lvl-base))
+}
+
+func signedOffset(n int) string {
+	if n > 0 {
+		return "+" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// Style returns the style of the level.
+//
+// A level uses the custom style of the closest level with a custom style below it,
+// unless a standard level (Debug, Info, Warn, Error) is closer, in which case the style
+// from the theme is used.
+func (l *Levels) Style(theme *Theme, lvl slog.Level) LevelStyle {
+	if l == nil || len(l.styles) == 0 {
+		return theme.Level(lvl)
+	}
+	if style, ok := l.styles[lvl]; ok {
+		return style
+	}
+	var (
+		best  slog.Level
+		found bool
+	)
+	for custom := range l.styles {
+		if custom <= lvl && (!found || custom > best) {
+			best, found = custom, true
+		}
+	}
+	if !found {
+		return theme.Level(lvl)
+	}
+	for _, std := range standardLevels {
+		if std <= lvl && std > best {
+			return theme.Level(lvl)
+		}
+	}
+	return l.styles[best]
+}
+
+// Parse parses a level name produced by [Levels.Name] or [slog.Level.String] back to a level.
+// Names are matched case-insensitively, e.g. "TRACE", "trace+2", "ERROR-1" or "WARN".
+func (l *Levels) Parse(s string) (slog.Level, error) {
+	if l != nil {
+		name, offset := s, 0
+		if i := strings.LastIndexAny(s, "+-"); i > 0 {
+			if n, err := strconv.Atoi(s[i:]); err == nil {
+				name, offset = s[:i], n
+			}
+		}
+		// Iterate in a stable order so duplicate names resolve deterministically.
+		for _, lvl := range slices.Sorted(maps.Keys(l.names)) {
+			if strings.EqualFold(l.names[lvl], name) {
+				return lvl + slog.Level(offset), nil
+			}
+		}
+	}
+	var lvl slog.Level
+	err := lvl.UnmarshalText([]byte(s))
+	return lvl, err
+}
+
+// withNames returns a copy of l with the given names added.
+func (l *Levels) withNames(names map[slog.Level]string) *Levels {
+	cp := l.clone()
+	if cp.names == nil {
+		cp.names = make(map[slog.Level]string, len(names))
+	}
+	maps.Copy(cp.names, names)
+	return cp
+}
+
+// withStyles returns a copy of l with the given styles added.
+func (l *Levels) withStyles(styles map[slog.Level]LevelStyle) *Levels {
+	cp := l.clone()
+	if cp.styles == nil {
+		cp.styles = make(map[slog.Level]LevelStyle, len(styles))
+	}
+	maps.Copy(cp.styles, styles)
+	return cp
+}
+
+func (l *Levels) clone() *Levels {
+	if l == nil {
+		return &Levels{}
+	}
+	return &Levels{
+		names:  maps.Clone(l.names),
+		styles: maps.Clone(l.styles),
+	}
+}
+
+// levelStyleOf returns the style of the record level, taking custom level styles into account.
+func levelStyleOf(info RecordData) LevelStyle {
+	return info.Levels.Style(themeOf(info), info.Record.Level)
+}
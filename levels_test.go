@@ -0,0 +1,143 @@
+package prettylog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+const (
+	testLevelTrace = slog.Level(-8)
+	testLevelFatal = slog.Level(12)
+)
+
+func testLevels() *Levels {
+	return (*Levels)(nil).
+		withNames(map[slog.Level]string{testLevelTrace: "TRACE", testLevelFatal: "FATAL"}).
+		withStyles(map[slog.Level]LevelStyle{
+			testLevelTrace: {Text: Style{color.FgBlue}},
+			testLevelFatal: {Text: Style{color.FgMagenta}},
+		})
+}
+
+func TestLevelsName(t *testing.T) {
+	levels := testLevels()
+	tests := []struct {
+		level    slog.Level
+		expected string
+	}{
+		{testLevelTrace, "TRACE"},
+		{testLevelTrace + 2, "TRACE+2"},
+		{testLevelTrace - 1, "TRACE-1"},
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo + 1, "INFO+1"},
+		{slog.LevelError + 2, "ERROR+2"},
+		{testLevelFatal, "FATAL"},
+		{testLevelFatal + 4, "FATAL+4"},
+	}
+	for _, tt := range tests {
+		if got := levels.Name(tt.level); got != tt.expected {
+			t.Errorf("Name(%d): expected %q, got %q", tt.level, tt.expected, got)
+		}
+	}
+
+	var nilLevels *Levels
+	if got := nilLevels.Name(slog.LevelWarn + 1); got != "WARN+1" {
+		t.Errorf("expected nil levels to behave like slog, got %q", got)
+	}
+}
+
+func TestLevelsStyle(t *testing.T) {
+	levels := testLevels()
+	tests := []struct {
+		level    slog.Level
+		expected LevelStyle
+	}{
+		{testLevelTrace, levels.styles[testLevelTrace]},
+		{testLevelTrace + 2, levels.styles[testLevelTrace]},
+		{testLevelTrace - 1, DefaultTheme.Other},
+		{slog.LevelDebug, DefaultTheme.Debug},
+		{slog.LevelError + 2, DefaultTheme.Error},
+		{testLevelFatal, levels.styles[testLevelFatal]},
+		{testLevelFatal + 4, levels.styles[testLevelFatal]},
+	}
+	for _, tt := range tests {
+		got := levels.Style(DefaultTheme, tt.level)
+		if got.Text[0] != tt.expected.Text[0] {
+			t.Errorf("Style(%d): expected %v, got %v", tt.level, tt.expected, got)
+		}
+	}
+}
+
+func TestLevelsParse(t *testing.T) {
+	levels := testLevels()
+	tests := []struct {
+		name     string
+		expected slog.Level
+	}{
+		{"TRACE", testLevelTrace},
+		{"trace+2", testLevelTrace + 2},
+		{"FATAL", testLevelFatal},
+		{"WARN", slog.LevelWarn},
+		{"ERROR-1", slog.LevelError - 1},
+	}
+	for _, tt := range tests {
+		got, err := levels.Parse(tt.name)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error %v", tt.name, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("Parse(%q): expected %d, got %d", tt.name, tt.expected, got)
+		}
+		if back, _ := levels.Parse(levels.Name(got)); back != got {
+			t.Errorf("Parse(Name(%d)) = %d", got, back)
+		}
+	}
+	if _, err := levels.Parse("VERBOSE"); err == nil {
+		t.Error("expected error for unknown level name")
+	}
+}
+
+func TestWithLevelNamesAndStyles(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(true),
+		WithLevel(testLevelTrace),
+		WithLevelNames(map[slog.Level]string{testLevelTrace: "TRACE"}),
+		WithLevelNames(map[slog.Level]string{testLevelFatal: "FATAL"}),
+		WithLevelStyles(map[slog.Level]LevelStyle{
+			testLevelFatal: {Text: Style{color.FgMagenta}, Background: Style{color.BgMagenta}},
+		}),
+		WithWriters(DefaultLevelWriter, DefaultMessageWriter, DefaultNewLineWriter),
+	)
+	logger := slog.New(handler)
+	logger.Log(context.Background(), testLevelTrace, "tracing")
+	logger.Log(context.Background(), testLevelFatal, "dying")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], " TRACE ") {
+		t.Errorf("expected TRACE level name, got %q", lines[0])
+	}
+	wantLevel := color.New(color.BgMagenta, color.Bold).Sprint(" FATAL ")
+	wantMessage := color.New(color.FgMagenta).Sprint("dying")
+	if !strings.Contains(lines[1], wantLevel) || !strings.Contains(lines[1], wantMessage) {
+		t.Errorf("expected FATAL level and message with custom style, got %q", lines[1])
+	}
+
+	if got := handler.Levels().Name(testLevelFatal); got != "FATAL" {
+		t.Errorf("expected handler levels to include FATAL, got %q", got)
+	}
+}
@@ -0,0 +1,208 @@
+package prettylog
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// VisibleWidth returns the number of terminal columns s occupies, ignoring ANSI escape
+// sequences and taking wide and zero width runes into account (see [RuneWidth]).
+//
+// Use it instead of len to align text that may be styled or contain multibyte characters,
+// e.g. in custom [EntryWriter.KeyLen] implementations.
+func VisibleWidth(s string) int {
+	return visibleWidth(s)
+}
+
+// visibleWidth is [VisibleWidth] for strings and byte slices, so text styled into a buffer
+// is measured without copying it.
+func visibleWidth[T string | []byte](s T) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := ansiSequenceLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		if s[i] < utf8.RuneSelf {
+			width += RuneWidth(rune(s[i]))
+			i++
+			continue
+		}
+		// The conversion of at most utf8.UTFMax bytes does not allocate.
+		r, size := utf8.DecodeRuneInString(string(s[i:min(i+utf8.UTFMax, len(s))]))
+		width += RuneWidth(r)
+		i += size
+	}
+	return width
+}
+
+// StripANSI returns s without ANSI escape sequences.
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); {
+		if n := ansiSequenceLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+// TruncateWidth truncates s to at most width visible columns (see [VisibleWidth]), replacing
+// the cut part with ellipsis. ANSI escape sequences are kept, so styles are still reset
+// at the end of s. s is returned unchanged if it fits.
+func TruncateWidth(s string, width int, ellipsis string) string {
+	if VisibleWidth(s) <= width {
+		return s
+	}
+	limit := max(width-VisibleWidth(ellipsis), 0)
+	var sb strings.Builder
+	column := 0
+	cut := false
+	for i := 0; i < len(s); {
+		if n := ansiSequenceLen(s[i:]); n > 0 {
+			sb.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if cut {
+			continue
+		}
+		if column+RuneWidth(r) > limit {
+			sb.WriteString(ellipsis)
+			cut = true
+			continue
+		}
+		sb.WriteRune(r)
+		column += RuneWidth(r)
+	}
+	return sb.String()
+}
+
+// ansiSequenceLen returns the length of the ANSI escape sequence s starts with, or 0.
+//
+// CSI sequences (e.g. SGR colors) and OSC sequences (e.g. hyperlinks) are recognized.
+func ansiSequenceLen[T string | []byte](s T) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	default:
+		return 2
+	}
+}
+
+// RuneWidth returns the number of terminal columns r occupies: 0 for control and
+// combining runes, 2 for East Asian wide and fullwidth runes and emoji, and 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0, r < 0x20, r >= 0x7f && r < 0xa0:
+		return 0
+	case r < 0x300:
+		return 1
+	case r == 0x200b, r == 0x200c, r == 0x200d, r == 0x2060, r == 0xfeff:
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r < wide[0] {
+			break
+		}
+		if r <= wide[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// wideRanges are the sorted ranges of East Asian wide and fullwidth runes and emoji.
+var wideRanges = [...][2]rune{
+	{0x1100, 0x115f},   // Hangul Jamo
+	{0x231a, 0x231b},   // Watch, hourglass
+	{0x2329, 0x232a},   // Angle brackets
+	{0x23e9, 0x23ec},   // Media controls
+	{0x23f0, 0x23f0},   // Alarm clock
+	{0x23f3, 0x23f3},   // Hourglass
+	{0x25fd, 0x25fe},   // Small squares
+	{0x2614, 0x2615},   // Umbrella, hot beverage
+	{0x2648, 0x2653},   // Zodiac
+	{0x267f, 0x267f},   // Wheelchair
+	{0x2693, 0x2693},   // Anchor
+	{0x26a1, 0x26a1},   // High voltage
+	{0x26aa, 0x26ab},   // Circles
+	{0x26bd, 0x26be},   // Balls
+	{0x26c4, 0x26c5},   // Snowman, sun
+	{0x26ce, 0x26ce},   // Ophiuchus
+	{0x26d4, 0x26d4},   // No entry
+	{0x26ea, 0x26ea},   // Church
+	{0x26f2, 0x26f3},   // Fountain, golf
+	{0x26f5, 0x26f5},   // Sailboat
+	{0x26fa, 0x26fa},   // Tent
+	{0x26fd, 0x26fd},   // Fuel pump
+	{0x2705, 0x2705},   // Check mark
+	{0x270a, 0x270b},   // Fists
+	{0x2728, 0x2728},   // Sparkles
+	{0x274c, 0x274c},   // Cross mark
+	{0x274e, 0x274e},   // Cross mark
+	{0x2753, 0x2755},   // Question marks
+	{0x2757, 0x2757},   // Exclamation mark
+	{0x2795, 0x2797},   // Math signs
+	{0x27b0, 0x27b0},   // Curly loop
+	{0x27bf, 0x27bf},   // Double curly loop
+	{0x2b1b, 0x2b1c},   // Large squares
+	{0x2b50, 0x2b50},   // Star
+	{0x2b55, 0x2b55},   // Circle
+	{0x2e80, 0x303e},   // CJK radicals, symbols and punctuation
+	{0x3041, 0x33ff},   // Hiragana, Katakana, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xa960, 0xa97f},   // Hangul Jamo extended A
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe10, 0xfe19},   // Vertical forms
+	{0xfe30, 0xfe6f},   // CJK compatibility forms, small forms
+	{0xff00, 0xff60},   // Fullwidth forms
+	{0xffe0, 0xffe6},   // Fullwidth signs
+	{0x16fe0, 0x16fe4}, // Ideographic symbols
+	{0x17000, 0x18cff}, // Tangut, Khitan
+	{0x1aff0, 0x1b2ff}, // Kana extensions, Nushu
+	{0x1f004, 0x1f004}, // Mahjong tile
+	{0x1f0cf, 0x1f0cf}, // Playing card
+	{0x1f18e, 0x1f18e}, // AB button
+	{0x1f191, 0x1f19a}, // Squared words
+	{0x1f200, 0x1f2ff}, // Enclosed ideographic supplement
+	{0x1f300, 0x1f64f}, // Pictographs, emoticons
+	{0x1f680, 0x1f6ff}, // Transport and map symbols
+	{0x1f7e0, 0x1f7eb}, // Colored circles and squares
+	{0x1f90c, 0x1f9ff}, // Supplemental symbols and pictographs
+	{0x1fa70, 0x1faff}, // Symbols and pictographs extended A
+	{0x20000, 0x3fffd}, // CJK extensions B and beyond
+}
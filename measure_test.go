@@ -0,0 +1,59 @@
+package prettylog
+
+import "testing"
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"ascii", "hello", 5},
+		{"ansi", "\x1b[1m\x1b[31mhello\x1b[0m", 5},
+		{"hyperlink", "\x1b]8;;file:///a.go\x1b\\a.go\x1b]8;;\x1b\\", 4},
+		{"cjk", "日本語", 6},
+		{"hangul", "한국", 4},
+		{"emoji", "ok 🚀", 5},
+		{"combining", "é", 1},
+		{"fullwidth", "ＡＢ", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VisibleWidth(tt.input); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	input := "\x1b[1m\x1b[31mbold red\x1b[0m and \x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\"
+	if got := StripANSI(input); got != "bold red and link" {
+		t.Errorf("unexpected result %q", got)
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		expected string
+	}{
+		{"fits", "short", 10, "short"},
+		{"ascii", "verylongkey", 6, "veryl…"},
+		{"wide runes", "日本語テキスト", 7, "日本語…"},
+		{"keeps ansi", "\x1b[31mverylongkey\x1b[0m", 6, "\x1b[31mveryl…\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateWidth(tt.input, tt.width, DefaultEllipsis)
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+			if w := VisibleWidth(got); w > tt.width {
+				t.Errorf("result is %d columns wide, more than %d", w, tt.width)
+			}
+		})
+	}
+}
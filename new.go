@@ -4,8 +4,6 @@ import (
 	"io"
 	"log/slog"
 	"os"
-
-	"github.com/mattn/go-isatty"
 )
 
 // DefaultWriters is the default set of entry writers used by new handlers.
@@ -27,8 +25,9 @@ var DefaultWriters = [...]EntryWriter{
 //   - Output to stderr
 //   - Info level logging
 //   - Source information enabled
-//   - Color support auto-detected based on terminal capabilities
+//   - Color support and depth auto-detected based on terminal capabilities and environment, see [DetectColorDepth]
 //   - All default writers enabled
+//   - Call sites cached, see [WithFrameCacheSize]
 //
 // The handler can be customized using Option functions.
 func New(opts ...Option) *Handler {
@@ -39,12 +38,15 @@ func New(opts ...Option) *Handler {
 			Level:     slog.LevelInfo,
 		},
 		pool:        newLimitedPool(defaultLimitedPoolSize),
-		attrs:       []slog.Attr{},
 		groups:      []string{},
-		color:       CanColor(os.Stderr),
+		colorDepth:  DetectColorDepth(os.Stderr),
 		packageName: "",
 		writers:     DefaultWriters[:],
+		theme:       DefaultTheme,
+		clock:       newRecordClock(),
+		frames:      newFrameCache(DefaultFrameCacheSize),
 	}
+	h.color = h.colorDepth != ColorNone
 	for _, opt := range opts {
 		if opt == nil {
 			continue
@@ -54,28 +56,18 @@ func New(opts ...Option) *Handler {
 	return h
 }
 
-// CanColor determines if the given writer supports pretty output (i.e., is a terminal and has tty).
+// CanColor determines if the given writer supports pretty output.
 //
 // [CanColor] is called by [New] to determine if pretty output should be enabled (overrideable),
 // but will not be called by [Handler.Clone].
 //
-// It does so by checking if the writer has an Fd() method and returns a file descriptor. This is typically implemented by [os.File].
+// Environment conventions (NO_COLOR, FORCE_COLOR, CLICOLOR, CLICOLOR_FORCE and TERM=dumb) are
+// honored as described in [DetectColorDepth]. Otherwise, it checks if the writer has an Fd() method
+// and returns a file descriptor of a terminal. This is typically implemented by [os.File].
 //
 // If the writer is wrapped (e.g., by a [WriteLocker]), it will attempt to unwrap if it implements interface { Unwrap() io.Writer }.
 // If available, then this function will check the underlying writer. This repeats
 // until a writer without Unwrap is found or a nil writer is encountered.
 func CanColor(w io.Writer) bool {
-	for {
-		if w == nil {
-			return false
-		}
-		if fd, ok := (w).(interface{ Fd() uintptr }); ok {
-			return isatty.IsTerminal(fd.Fd())
-		}
-		if uw, ok := w.(interface{ Unwrap() io.Writer }); ok {
-			w = uw.Unwrap()
-			continue
-		}
-		return false
-	}
+	return DetectColorDepth(w) != ColorNone
 }
@@ -13,9 +13,12 @@ type Option func(h *Handler)
 // WithPackageName supplies the Handler with the package name to be used.
 // The package name is used by function formatters to trim package prefixes
 // from function names, providing cleaner output.
+//
+// Function names cached by the handler are dropped, as they depend on the package name.
 func WithPackageName(name string) Option {
 	return func(h *Handler) {
 		h.packageName = name
+		h.frames = h.frames.reset()
 	}
 }
 
@@ -71,6 +74,16 @@ func WithColor(enabled bool) Option {
 	}
 }
 
+// WithColorDepth sets the color depth of the output. Colors are enabled unless depth is [ColorNone].
+//
+// By default, the depth is detected from the environment by [DetectColorDepth].
+func WithColorDepth(depth ColorDepth) Option {
+	return func(h *Handler) {
+		h.colorDepth = depth
+		h.color = depth != ColorNone
+	}
+}
+
 // WithWriters sets the complete list of entry writers for the handler.
 // This replaces all existing writers.
 func WithWriters(writers ...EntryWriter) Option {
@@ -122,6 +135,19 @@ func WithPoolSize(maxSize int) Option {
 	}
 }
 
+// WithFrameCacheSize sets the number of call sites cached by the handler. Default is [DefaultFrameCacheSize].
+//
+// The frame of each call site is resolved from [slog.Record.PC] once, and the file and
+// function strings of the built-in formatters are formatted once, so repeated log sites cost
+// a map lookup. A size of 0 or less disables the cache.
+//
+// The cache is shared with handlers derived from this one (e.g. by [Handler.WithAttrs] or [Handler.Clone]).
+func WithFrameCacheSize(size int) Option {
+	return func(h *Handler) {
+		h.frames = newFrameCache(size)
+	}
+}
+
 // AddWritersBefore add writers before given tgt and unshift tgt up if found.
 //
 // If not found or tgt is nil, the writers are appended to the end.
@@ -162,3 +188,250 @@ func AddWritersAfter(tgt EntryWriter, writers ...EntryWriter) Option {
 		)
 	}
 }
+
+// WithLayout sets the layout of the handler and replaces the entry writers
+// with the preset for that layout ([DefaultWriters] for [ColumnLayout] and
+// [CompactWriters] for [CompactLayout]).
+//
+// Because the writers are replaced, options that modify writers should come
+// after WithLayout.
+func WithLayout(layout Layout) Option {
+	return func(h *Handler) {
+		h.layout = layout
+		switch layout {
+		case CompactLayout:
+			h.writers = slices.Clone(CompactWriters[:])
+		default:
+			h.writers = slices.Clone(DefaultWriters[:])
+		}
+	}
+}
+
+// WithTemplate replaces all entry writers with tw. See [TemplateWriter] for the template syntax.
+//
+// Use [ParseTemplate] to handle errors of templates from configuration, and [MustParseTemplate]
+// for template literals. tw must not be nil: WithTemplate panics on nil, so an ignored parse error
+// does not silently keep the previous writers.
+//
+// Example:
+//
+//	tw, err := prettylog.ParseTemplate(cfg.LogTemplate)
+//	if err != nil {
+//	    return err
+//	}
+//	handler := prettylog.New(prettylog.WithTemplate(tw))
+func WithTemplate(tw *TemplateWriter) Option {
+	if tw == nil {
+		panic("prettylog: WithTemplate called with a nil template")
+	}
+	return func(h *Handler) {
+		h.writers = []EntryWriter{tw}
+	}
+}
+
+// WithAsync enables the asynchronous mode of the handler.
+//
+// In asynchronous mode, records are rendered by the logging goroutine but written to
+// the output by a background goroutine, so a slow output does not stall the callers.
+// Up to queueSize rendered records are kept in memory. If queueSize <= 0, a default size of 1024 is used.
+// policy determines what happens when the queue is full. Dropped records are counted by [Handler.Dropped].
+//
+// Call [Handler.Close] on shutdown to drain the queue, and [Handler.Flush] to wait for
+// queued records to be written.
+//
+// Every call to WithAsync starts a new background goroutine, which is stopped by [Handler.Close].
+func WithAsync(queueSize int, policy OverflowPolicy) Option {
+	return func(h *Handler) {
+		h.async = newAsyncWriter(queueSize, policy)
+	}
+}
+
+// Output is a destination for [WithOutputs].
+type Output struct {
+	// Writer is the destination of the log records.
+	// It will be wrapped with [WriteLocker] if it doesn't already implement it.
+	Writer io.Writer
+	// Color enables colored output for this destination.
+	Color bool
+	// Writers are the entry writers used to render records for this destination.
+	// If nil, the entry writers of the handler are used.
+	Writers []EntryWriter
+}
+
+type handlerOutput struct {
+	writer  WriteLocker
+	color   bool
+	writers []EntryWriter
+}
+
+// WithOutputs makes the handler write every record to all the given outputs, each with its own
+// color setting and entry writers. A record is rendered once per distinct combination of color
+// and entry writers, and errors from the outputs are combined with [errors.Join].
+//
+// When outputs are given, the output and color set by [WithOutput] and [WithColor] are ignored.
+// Calling WithOutputs without outputs restores them.
+//
+// Example of colored output to stderr with a color-free copy in a file:
+//
+//	handler := prettylog.New(
+//	    prettylog.WithOutputs(
+//	        prettylog.Output{Writer: os.Stderr, Color: prettylog.CanColor(os.Stderr)},
+//	        prettylog.Output{Writer: file, Color: false},
+//	    ),
+//	)
+func WithOutputs(outputs ...Output) Option {
+	return func(h *Handler) {
+		h.outputs = make([]handlerOutput, 0, len(outputs))
+		for _, out := range outputs {
+			if out.Writer == nil {
+				continue
+			}
+			h.outputs = append(h.outputs, handlerOutput{
+				writer:  WrapWriteLocker(out.Writer),
+				color:   out.Color,
+				writers: out.Writers,
+			})
+		}
+	}
+}
+
+// WithRedaction redacts secrets and personal information before any [EntryWriter] sees them.
+//
+// Rules are applied to the log message, the attributes of the record and the attributes
+// collected by [Handler.WithAttrs]. Key rules are checked against the full key path
+// (groups and key joined by dots, e.g. "http.headers.authorization"), and value rules
+// are applied to the message and to string values, errors, [fmt.Stringer]s and byte slices.
+// See [RedactKey] and [RedactValue].
+//
+// Attributes already collected by [Handler.WithAttrs] are redacted when this option is applied.
+// Calling WithRedaction again replaces the rules, but cannot restore already redacted attributes.
+//
+// Example:
+//
+//	handler := prettylog.New(
+//	    prettylog.WithRedaction(
+//	        prettylog.RedactKey(prettylog.MatchKeyGlob("**.authorization"), prettylog.MaskFull("")),
+//	        prettylog.RedactValue(prettylog.MatchJWT(), prettylog.MaskKeepLast(4)),
+//	        prettylog.RedactValue(prettylog.MatchEmail(), prettylog.MaskHash("salt")),
+//	    ),
+//	)
+func WithRedaction(rules ...RedactionRule) Option {
+	return func(h *Handler) {
+		if len(rules) == 0 {
+			h.redactor = nil
+			return
+		}
+		h.redactor = newRedactor(rules)
+		grouped := make([]GroupedAttrs, len(h.groupedAttrs))
+		for i, ga := range h.groupedAttrs {
+			grouped[i] = GroupedAttrs{
+				Groups: ga.Groups,
+				Attrs:  h.redactor.attrs(ga.Groups, ga.Attrs),
+			}
+		}
+		h.groupedAttrs = grouped
+	}
+}
+
+// WithLevelRules sets minimum levels for specific packages or functions, overriding
+// the level set by [WithLevel] for records logged from them.
+//
+// Keys are package paths or function name prefixes, matched against the fully qualified
+// function name of the caller (the same name used by [ShortFunctionFormat]) at package or symbol
+// boundaries: "github.com/acme/db" matches "github.com/acme/db.Open" and the subpackage
+// "github.com/acme/db/pool.New", but not "github.com/acme/dbx.Open". When multiple keys match, the
+// longest wins. Records from callers without a matching rule use the handler level.
+//
+// The matching rule of every log site is cached by program counter, so the cost after the first
+// record is a single map lookup. Levels are read on every record, so [slog.LevelVar] values
+// can be changed at runtime.
+//
+// Example:
+//
+//	handler := prettylog.New(
+//	    prettylog.WithLevel(slog.LevelInfo),
+//	    prettylog.WithLevelRules(map[string]slog.Leveler{
+//	        "github.com/acme/db":                   slog.LevelDebug,
+//	        "github.com/acme/http.(*Server).serve": slog.LevelWarn,
+//	    }),
+//	)
+func WithLevelRules(rules map[string]slog.Leveler) Option {
+	return func(h *Handler) {
+		if len(rules) == 0 {
+			h.levelRules = nil
+			return
+		}
+		h.levelRules = newLevelRules(rules)
+	}
+}
+
+// WithTheme sets the theme used by the built-in [Styler]s and attribute writers.
+// See [DefaultTheme], [DarkTheme], [LightTheme], [SolarizedTheme] and [MonochromeBoldTheme].
+//
+// If theme is nil, [DefaultTheme] is used.
+func WithTheme(theme *Theme) Option {
+	return func(h *Handler) {
+		h.theme = theme
+	}
+}
+
+// WithLevelNames sets the display names of levels, e.g. for non-standard levels:
+//
+//	const (
+//		LevelTrace = slog.Level(-8)
+//		LevelFatal = slog.Level(12)
+//	)
+//
+//	prettylog.WithLevelNames(map[slog.Level]string{LevelTrace: "TRACE", LevelFatal: "FATAL"})
+//
+// Levels between named levels are rendered relative to the closest named level below,
+// like [slog.Level.String] does, e.g. "TRACE+2". Names are merged with the ones set
+// by previous calls.
+func WithLevelNames(names map[slog.Level]string) Option {
+	return func(h *Handler) {
+		h.levels = h.levels.withNames(names)
+	}
+}
+
+// WithLevelStyles sets the styles of levels, overriding the styles of the theme.
+//
+// A level without a style uses the style of the closest level below it, e.g. with a style
+// for level 12 (FATAL), levels 12 and above use it while levels 8 to 11 use the error style of the theme.
+// Styles are merged with the ones set by previous calls.
+func WithLevelStyles(styles map[slog.Level]LevelStyle) Option {
+	return func(h *Handler) {
+		h.levels = h.levels.withStyles(styles)
+	}
+}
+
+// WithWrap enables wrapping of long messages and [CommonWriter] values to the terminal width.
+//
+// The width is detected from the output's file descriptor, falling back to the COLUMNS environment
+// variable and then to opts.Width. COLUMNS is read when the option is applied, and the width of
+// terminals is read again at most once per second to follow resizes. Values are soft-wrapped with a hanging indent aligned to the
+// value column, and are truncated with an ellipsis beyond opts.MaxLines lines if set.
+// Widths are measured on visible text, ignoring ANSI codes and counting wide runes as two columns.
+//
+// Wrapping only applies to [ColumnLayout]. [CompactLayout] keeps entries on a single line.
+func WithWrap(opts WrapOptions) Option {
+	return func(h *Handler) {
+		h.wrap = newWrapResolver(opts)
+	}
+}
+
+// WithKeyAlignment sets the alignment of keys in the key column of [ColumnLayout].
+// See [KeyAlignLeft], [KeyAlignRight] and [KeyAlignFixed].
+func WithKeyAlignment(align KeyAlignment) Option {
+	return func(h *Handler) {
+		h.keyAlignment = align
+	}
+}
+
+// WithKeyWidth sets a fixed width for the key column and sets the alignment to [KeyAlignFixed].
+// Keys wider than width are truncated with an ellipsis.
+func WithKeyWidth(width int) Option {
+	return func(h *Handler) {
+		h.keyAlignment = KeyAlignFixed
+		h.keyWidth = width
+	}
+}
@@ -36,12 +36,25 @@
 //
 //   - WithPackageName(string): Set package name for function trimming
 //   - WithOutput(io.Writer): Set output destination
+//   - WithOutputs(...Output): Write to multiple destinations, each with its own color and writers
 //   - WithLevel(slog.Level): Set minimum log level
+//   - WithLevelRules(map[string]slog.Leveler): Set minimum log levels per package or function
 //   - WithAddSource(bool): Enable/disable source information
 //   - WithReplaceAttr(func): Set attribute replacement function
 //   - WithHandlerOptions(*slog.HandlerOptions): Set complete handler options
 //   - WithColor(bool): Enable/disable colored output
+//   - WithColorDepth(ColorDepth): Set the number of colors supported by the output
+//   - WithTheme(*Theme): Set the colors used by stylers and attribute writers
+//   - WithLevelNames(map[slog.Level]string): Set display names of custom levels
+//   - WithLevelStyles(map[slog.Level]LevelStyle): Set colors of custom levels
 //   - WithPoolSize(int): Set buffer pool size
+//   - WithFrameCacheSize(int): Set the number of call sites whose source information is cached
+//   - WithLayout(Layout): Use a preset layout (ColumnLayout or CompactLayout)
+//   - WithWrap(WrapOptions): Wrap and truncate long values to the terminal width
+//   - WithKeyAlignment(KeyAlignment): Align keys to the left or right of the key column
+//   - WithKeyWidth(int): Use a fixed width for the key column
+//   - WithAsync(int, OverflowPolicy): Write to the output from a background goroutine
+//   - WithRedaction(...RedactionRule): Mask secrets and personal information
 //
 // # Writer Management
 //
@@ -77,6 +90,7 @@
 //   - WithAdditionalWriters(...EntryWriter): Add to existing writers
 //   - WithoutWriters(...EntryWriter): Remove specific writers
 //   - ReplaceWriter(old, new): Replace a specific writer
+//   - WithTemplate(*TemplateWriter): Replace all writers with a template from ParseTemplate, e.g. "{time} {level} {message}\n{attrs}"
 //
 // # Entry Writers
 //
@@ -88,6 +102,10 @@
 //   - DefaultFunctionWriter: Function name with optional package trimming
 //   - DefaultFileLineWriter: File path and line number
 //   - DefaultPrettyJSONWriter: Pretty-printed JSON for structured data
+//   - DefaultLogfmtWriter: Single line key=value alternative to DefaultPrettyJSONWriter
+//   - DefaultErrorWriter: Error chains and stack traces of error attributes (not enabled by default)
+//   - DefaultStackTraceWriter: Call stack of error level records (not enabled by default)
+//   - DefaultSourceSnippetWriter: Source code around the caller of warning and error records (not enabled by default)
 //
 // Each writer can be individually customized using their With* methods or replaced entirely.
 //
@@ -119,4 +137,39 @@
 //	handler := prettylog.New(
 //	    prettylog.WithPoolSize(32 * 1024 * 1024), // 32MB pool
 //	)
+//
+// The caller frame and the formatted file and function names of each call site are cached,
+// so repeated log sites are not symbolized again. See [WithFrameCacheSize].
+//
+// If the output is slow (e.g. a busy terminal or pipe), the asynchronous mode moves
+// the writing to a background goroutine. Remember to drain the queue on shutdown:
+//
+//	handler := prettylog.New(
+//	    prettylog.WithAsync(4096, prettylog.OverflowDropOldest),
+//	)
+//	defer handler.Close()
+//
+// # Log Files
+//
+// [RotatingFile] writes to a file rotated by size or at the start of every hour or day,
+// keeping a bounded number of optionally compressed backups:
+//
+//	file := prettylog.NewRotatingFile("/var/log/app.log").
+//	    WithMaxSize(100 << 20).
+//	    WithMaxBackups(7).
+//	    WithReopenOnSIGHUP()
+//	defer file.Close()
+//	handler := prettylog.New(prettylog.WithOutput(file))
+//
+// # Recent Logs
+//
+// [RingBuffer] retains the last records in memory and serves them over HTTP as text, as a live
+// colored HTML page, or as a Server-Sent Events stream:
+//
+//	ring := prettylog.NewRingBuffer(500)
+//	handler := prettylog.New(prettylog.WithOutputs(
+//	    prettylog.Output{Writer: os.Stderr, Color: prettylog.CanColor(os.Stderr)},
+//	    prettylog.Output{Writer: ring, Color: true},
+//	))
+//	debugMux.Handle("/debug/logs", ring)
 package prettylog
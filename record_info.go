@@ -5,6 +5,8 @@ import (
 	"context"
 	"log/slog"
 	"runtime"
+	"strings"
+	"time"
 )
 
 // RecordData contains contextual information about the current log record being processed.
@@ -27,15 +29,70 @@ type RecordData struct {
 	// It's cached here to avoid multiple calls to [runtime.CallerFrames] or [runtime.FuncForPC] inside formatters and stylers.
 	//
 	// Do note that if [runtime.Frame.Func] is nil, it means that the function information is not available. Ensure to take note of that into account when using it.
+	// File, Line and Function may still be set when Func is nil, e.g. for inlined functions or frames given by [ContextWithFrame].
 	Frame runtime.Frame
 
+	// Callers are the program counters of the call stack of the logging call, captured by
+	// [Handler.Handle] from its caller onwards, so the frames of log/slog come first.
+	//
+	// It is only captured if a writer of the handler needs it, like [StackTraceWriter], and is nil otherwise.
+	Callers []uintptr
+
+	// HandlerAttrs are the attributes collected by [Handler.WithAttrs], in the order
+	// they were added. Each entry remembers the groups opened by [Handler.WithGroup]
+	// at the time the attributes were added, following the same nesting semantics
+	// as [slog.JSONHandler].
+	//
+	// Do not modify the slices, they are shared with the handler.
+	HandlerAttrs []GroupedAttrs
+
+	// Groups are the groups opened by [Handler.WithGroup]. Attributes of the
+	// [slog.Record] are nested under these groups.
+	//
+	// Do not modify the slice, it is shared with the handler.
+	Groups []string
+
 	// Color indicates if output should be colored or not.
 	//
 	// If false, it means the text output must not have ANSI color codes.
 	Color bool
 
-	// KeyFieldLength is the length of the longest key field from all the [EntryWriter] after
-	// including ANSI color codes.
+	// ColorDepth is the number of colors supported by the output. It is [ColorNone]
+	// if Color is false, and at least [Color16] otherwise.
+	//
+	// Stylers may use it to degrade extended colors, see [Style.Degrade].
+	ColorDepth ColorDepth
+
+	// Theme is the theme set by [WithTheme] option. Built-in [Styler]s and attribute
+	// writers take their colors from it. It is never nil when passed by [Handler].
+	Theme *Theme
+
+	// Levels are the custom level names and styles set by [WithLevelNames] and [WithLevelStyles].
+	// It may be nil, which is valid and behaves like the standard slog levels.
+	Levels *Levels
+
+	// Start is the time the handler was created by [New]. Handlers derived from it
+	// (e.g. by [Handler.WithAttrs] or [Handler.Clone]) share the same start time.
+	Start time.Time
+
+	// Previous is the time of the previous record handled by the handler or the handlers
+	// derived from it. It is the zero time for the first record.
+	Previous time.Time
+
+	// Layout is the layout set by [WithLayout] option.
+	Layout Layout
+
+	// KeyAlignment is the alignment of the key column set by [WithKeyAlignment] option.
+	KeyAlignment KeyAlignment
+
+	// Wrap is the wrapping set by [WithWrap] option, with the width resolved for the output.
+	// It is nil if wrapping is disabled or the width is unknown. It is shared between records
+	// and must not be modified.
+	Wrap *WrapOptions
+
+	// KeyFieldLength is the visible width of the widest key from all the [EntryWriter]s,
+	// as reported by [EntryWriter.KeyLen], or the width set by [WithKeyWidth] if
+	// KeyAlignment is [KeyAlignFixed].
 	//
 	// This value is zero when passed into [EntryWriter.KeyLen] (because it is not known yet).
 	KeyFieldLength int
@@ -59,4 +116,74 @@ type RecordData struct {
 	//
 	// If you have to keep hold of the value, make a copy of the buffer
 	Buffer *bytes.Buffer
+
+	// frameEntry is the frame cache entry of the call site, if the handler caches frames.
+	frameEntry *frameEntry
+}
+
+// GroupedAttrs is a set of attributes added by [Handler.WithAttrs]
+// together with the group path that was open when they were added.
+type GroupedAttrs struct {
+	// Groups is the group path the attributes are nested under.
+	Groups []string
+	// Attrs are the attributes added.
+	Attrs []slog.Attr
+}
+
+// WalkAttrs calls fn for every attribute to be logged, starting with the attributes
+// collected by [Handler.WithAttrs] followed by the attributes of the [slog.Record].
+//
+// Groups are flattened: fn is only called with non-group attributes, and groups holds
+// the full group path of the attribute (including groups opened by [Handler.WithGroup]).
+// Values are resolved, [slog.HandlerOptions.ReplaceAttr] is applied, and empty
+// attributes are skipped, following the same rules as [slog.JSONHandler].
+//
+// Iteration stops when fn returns false. Do not retain the groups slice after fn returns.
+func (info RecordData) WalkAttrs(fn func(groups []string, a slog.Attr) bool) {
+	var replace replaceAttrFunc
+	if info.HandlerOptions != nil {
+		replace = info.HandlerOptions.ReplaceAttr
+	}
+	for _, ga := range info.HandlerAttrs {
+		for _, a := range ga.Attrs {
+			if !walkAttr(ga.Groups, a, replace, fn) {
+				return
+			}
+		}
+	}
+	info.Record.Attrs(func(a slog.Attr) bool {
+		return walkAttr(info.Groups, a, replace, fn)
+	})
+}
+
+func walkAttr(groups []string, a slog.Attr, replace replaceAttrFunc, fn func(groups []string, a slog.Attr) bool) bool {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup && replace != nil {
+		a = replace(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return true
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			if !walkAttr(groups, ga, replace, fn) {
+				return false
+			}
+		}
+		return true
+	}
+	return fn(groups, a)
+}
+
+// GroupedKey returns the key prefixed by the group path separated by dots,
+// e.g. "http.request.method".
+func GroupedKey(groups []string, key string) string {
+	if len(groups) == 0 {
+		return key
+	}
+	return strings.Join(groups, ".") + "." + key
 }
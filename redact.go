@@ -0,0 +1,303 @@
+package prettylog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMask is the replacement used by [MaskFull] when no mask is given.
+const DefaultMask = "[REDACTED]"
+
+// KeyMatcher reports whether an attribute must be redacted based on its full key path,
+// which is the group path and key joined by dots, e.g. "http.headers.authorization".
+type KeyMatcher func(path string) bool
+
+// ValueMatcher returns the [start, end) byte ranges of s that must be redacted,
+// in the same form as [regexp.Regexp.FindAllStringIndex]. The ranges must not overlap.
+type ValueMatcher func(s string) [][]int
+
+// Masker returns the replacement for a redacted value.
+type Masker func(s string) string
+
+// RedactionRule is a rule for [WithRedaction].
+//
+// Use [RedactKey] and [RedactValue] to create rules.
+type RedactionRule struct {
+	// Key, if set, redacts the whole value of attributes whose key path matches.
+	Key KeyMatcher
+	// Value, if set, redacts the matching parts of string values and of the log message.
+	// Errors, [fmt.Stringer]s and byte slices are matched against their text, and replaced
+	// by the masked text when anything matched.
+	Value ValueMatcher
+	// Mask is the masking strategy. If nil, [MaskFull] with [DefaultMask] is used.
+	Mask Masker
+}
+
+// RedactKey creates a rule that redacts the whole value of attributes whose key path matches.
+//
+// Non-string values are converted to string before being masked. If the key path
+// matches a group, the whole group is replaced by [DefaultMask], regardless of mask.
+func RedactKey(matcher KeyMatcher, mask Masker) RedactionRule {
+	return RedactionRule{Key: matcher, Mask: mask}
+}
+
+// RedactValue creates a rule that redacts the parts of string values (and of the log message) that match.
+//
+// Errors, [fmt.Stringer]s and byte slices are matched against their text. If anything matched,
+// the value is replaced by its masked text.
+func RedactValue(matcher ValueMatcher, mask Masker) RedactionRule {
+	return RedactionRule{Value: matcher, Mask: mask}
+}
+
+// MatchKeyGlob matches key paths against a glob pattern, case-insensitively.
+//
+// The pattern syntax is the one of [path.Match], with dots as separators: `*` matches
+// any sequence of characters within a single key, so "*.authorization" matches
+// "headers.authorization" but not "http.headers.authorization". Use "**" as a whole
+// segment to match any number of keys, e.g. "**.authorization".
+func MatchKeyGlob(pattern string) KeyMatcher {
+	pattern = strings.ToLower(pattern)
+	segments := strings.Split(pattern, ".")
+	return func(key string) bool {
+		return matchGlobSegments(segments, strings.Split(strings.ToLower(key), "."))
+	}
+}
+
+func matchGlobSegments(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(key); i++ {
+				if matchGlobSegments(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(key) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], key[0]); !ok {
+			return false
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
+
+// MatchKeyRegexp matches key paths against a regular expression.
+func MatchKeyRegexp(re *regexp.Regexp) KeyMatcher {
+	return re.MatchString
+}
+
+// MatchValueRegexp matches the parts of values that match the regular expression.
+func MatchValueRegexp(re *regexp.Regexp) ValueMatcher {
+	return func(s string) [][]int {
+		return re.FindAllStringIndex(s, -1)
+	}
+}
+
+var (
+	creditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	jwtPattern        = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// MatchCreditCard matches credit card numbers of 13 to 19 digits, optionally separated
+// by spaces or dashes, that pass the Luhn checksum.
+func MatchCreditCard() ValueMatcher {
+	return func(s string) [][]int {
+		var matches [][]int
+		for _, m := range creditCardPattern.FindAllStringIndex(s, -1) {
+			if luhnValid(s[m[0]:m[1]]) {
+				matches = append(matches, m)
+			}
+		}
+		return matches
+	}
+}
+
+// MatchJWT matches strings shaped like JSON Web Tokens.
+func MatchJWT() ValueMatcher {
+	return MatchValueRegexp(jwtPattern)
+}
+
+// MatchEmail matches email addresses.
+func MatchEmail() ValueMatcher {
+	return MatchValueRegexp(emailPattern)
+}
+
+func luhnValid(s string) bool {
+	sum, count := 0, 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		count++
+		double = !double
+	}
+	return count >= 13 && sum%10 == 0
+}
+
+// MaskFull replaces the whole value with mask, or [DefaultMask] if mask is empty.
+func MaskFull(mask string) Masker {
+	if mask == "" {
+		mask = DefaultMask
+	}
+	return func(string) string {
+		return mask
+	}
+}
+
+// MaskKeepLast replaces every character but the last n with '*'.
+func MaskKeepLast(n int) Masker {
+	return func(s string) string {
+		count := utf8.RuneCountInString(s)
+		if count <= n {
+			return strings.Repeat("*", count)
+		}
+		keep := s
+		for i := 0; i < count-n; i++ {
+			_, size := utf8.DecodeRuneInString(keep)
+			keep = keep[size:]
+		}
+		return strings.Repeat("*", count-n) + keep
+	}
+}
+
+// MaskHash replaces the value with a short SHA-256 hash of the salt and the value,
+// so equal values can still be correlated across log records without revealing them.
+func MaskHash(salt string) Masker {
+	return func(s string) string {
+		sum := sha256.Sum256([]byte(salt + s))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	}
+}
+
+// redactor applies redaction rules to attributes and messages.
+type redactor struct {
+	keyRules   []RedactionRule
+	valueRules []RedactionRule
+}
+
+func newRedactor(rules []RedactionRule) *redactor {
+	r := &redactor{}
+	for _, rule := range rules {
+		if rule.Mask == nil {
+			rule.Mask = MaskFull("")
+		}
+		if rule.Key != nil {
+			r.keyRules = append(r.keyRules, rule)
+		}
+		if rule.Value != nil {
+			r.valueRules = append(r.valueRules, rule)
+		}
+	}
+	return r
+}
+
+// record returns a copy of rec with the message and attributes redacted.
+// groups is the group path the attributes of rec are nested under.
+func (r *redactor) record(rec slog.Record, groups []string) slog.Record {
+	redacted := slog.NewRecord(rec.Time, rec.Level, r.text(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(r.attr(groups, a))
+		return true
+	})
+	return redacted
+}
+
+func (r *redactor) attrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = r.attr(groups, a)
+	}
+	return redacted
+}
+
+func (r *redactor) attr(groups []string, a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if len(r.keyRules) > 0 && a.Key != "" {
+		path := GroupedKey(groups, a.Key)
+		for _, rule := range r.keyRules {
+			if !rule.Key(path) {
+				continue
+			}
+			if a.Value.Kind() == slog.KindGroup {
+				// Partial masks would reveal parts of the rendered group.
+				return slog.String(a.Key, DefaultMask)
+			}
+			return slog.String(a.Key, rule.Mask(a.Value.String()))
+		}
+	}
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		a.Value = slog.GroupValue(r.attrs(groups, a.Value.Group())...)
+	case slog.KindString:
+		if s := a.Value.String(); s != "" {
+			if redacted := r.text(s); redacted != s {
+				a.Value = slog.StringValue(redacted)
+			}
+		}
+	case slog.KindAny:
+		if len(r.valueRules) == 0 {
+			break
+		}
+		if s, ok := anyText(a.Value.Any()); ok && s != "" {
+			if redacted := r.text(s); redacted != s {
+				a.Value = slog.StringValue(redacted)
+			}
+		}
+	}
+	return a
+}
+
+// anyText returns the text of values that commonly carry secrets: errors, [fmt.Stringer]s and byte slices.
+// fmt is used to call Error and String, so a panicking method does not crash the handler.
+func anyText(v any) (string, bool) {
+	switch v := v.(type) {
+	case error, fmt.Stringer:
+		return fmt.Sprint(v), true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+// text masks the parts of s matched by the value rules.
+func (r *redactor) text(s string) string {
+	for _, rule := range r.valueRules {
+		matches := rule.Value(s)
+		if len(matches) == 0 {
+			continue
+		}
+		var sb strings.Builder
+		last := 0
+		for _, m := range matches {
+			sb.WriteString(s[last:m[0]])
+			sb.WriteString(rule.Mask(s[m[0]:m[1]]))
+			last = m[1]
+		}
+		sb.WriteString(s[last:])
+		s = sb.String()
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+package prettylog
+
+import (
+	"sync"
+	"time"
+)
+
+var _ WriteLocker = (*RingBuffer)(nil)
+
+// DefaultRingBufferSize is the number of records retained by a [RingBuffer] created with a size of 0 or less.
+const DefaultRingBufferSize = 1000
+
+// ringSubscriberBuffer is the number of records queued for a slow subscriber before records are dropped.
+const ringSubscriberBuffer = 64
+
+// RingEntry is a record retained by a [RingBuffer], as rendered by the handler.
+type RingEntry struct {
+	// Seq is the sequence number of the record, starting at 1 for the first record written.
+	Seq uint64
+	// Time is the time the record was written to the buffer.
+	Time time.Time
+	// Text is the rendered record, with the ANSI escape sequences of colored outputs.
+	Text string
+}
+
+// Plain returns the rendered record without ANSI escape sequences.
+func (e RingEntry) Plain() string {
+	return StripANSI(e.Text)
+}
+
+// HTML returns the rendered record as HTML, with colors converted by [ANSIToHTML].
+func (e RingEntry) HTML() string {
+	return ANSIToHTML(e.Text)
+}
+
+// RingBuffer is a [WriteLocker] retaining the last records written to it in memory, to inspect
+// the recent logs of a service without log shipping. It is safe for concurrent use.
+//
+// Every write is retained as one record, as done by [Handler]. Colors are kept, so give it a
+// colored output to get both the colored and uncolored variants of the records (see [RingEntry]).
+//
+// RingBuffer implements [http.Handler] to serve the records, e.g. on a local debug mux:
+//
+//	ring := prettylog.NewRingBuffer(500)
+//	handler := prettylog.New(prettylog.WithOutputs(
+//	    prettylog.Output{Writer: os.Stderr, Color: prettylog.CanColor(os.Stderr)},
+//	    prettylog.Output{Writer: ring, Color: true},
+//	))
+//	debugMux.Handle("/debug/logs", ring)
+//
+// See [RingBuffer.ServeHTTP] for the available formats.
+type RingBuffer struct {
+	// mu is the lock of the WriteLocker interface, held by the handler while writing an entry.
+	mu sync.Mutex
+
+	entriesMu   sync.RWMutex
+	entries     []RingEntry
+	next        int
+	seq         uint64
+	subscribers map[chan RingEntry]struct{}
+}
+
+// NewRingBuffer creates a RingBuffer retaining the last size records.
+// If size is 0 or less, [DefaultRingBufferSize] records are retained.
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = DefaultRingBufferSize
+	}
+	return &RingBuffer{
+		entries:     make([]RingEntry, 0, size),
+		subscribers: make(map[chan RingEntry]struct{}),
+	}
+}
+
+// Lock implements [sync.Locker] interface.
+func (rb *RingBuffer) Lock() {
+	rb.mu.Lock()
+}
+
+// Unlock implements [sync.Locker] interface.
+func (rb *RingBuffer) Unlock() {
+	rb.mu.Unlock()
+}
+
+// Write implements [io.Writer] interface. p is retained as one record, evicting the oldest
+// record if the buffer is full.
+func (rb *RingBuffer) Write(p []byte) (n int, err error) {
+	rb.entriesMu.Lock()
+	defer rb.entriesMu.Unlock()
+
+	rb.seq++
+	entry := RingEntry{Seq: rb.seq, Time: time.Now(), Text: string(p)}
+	if len(rb.entries) < cap(rb.entries) {
+		rb.entries = append(rb.entries, entry)
+	} else {
+		rb.entries[rb.next] = entry
+		rb.next = (rb.next + 1) % len(rb.entries)
+	}
+	for ch := range rb.subscribers {
+		select {
+		case ch <- entry:
+		default:
+			// The subscriber is too slow, the record is dropped for it.
+		}
+	}
+	return len(p), nil
+}
+
+// Entries returns the retained records, oldest first.
+func (rb *RingBuffer) Entries() []RingEntry {
+	return rb.Since(0)
+}
+
+// Since returns the retained records with a sequence number greater than seq, oldest first.
+func (rb *RingBuffer) Since(seq uint64) []RingEntry {
+	rb.entriesMu.RLock()
+	defer rb.entriesMu.RUnlock()
+	return rb.since(seq)
+}
+
+func (rb *RingBuffer) since(seq uint64) []RingEntry {
+	out := make([]RingEntry, 0, len(rb.entries))
+	for i := range rb.entries {
+		if entry := rb.entries[(rb.next+i)%len(rb.entries)]; entry.Seq > seq {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// Reset removes the retained records. Sequence numbers keep increasing.
+func (rb *RingBuffer) Reset() {
+	rb.entriesMu.Lock()
+	defer rb.entriesMu.Unlock()
+	rb.entries = rb.entries[:0]
+	rb.next = 0
+}
+
+// subscribe returns the records retained after seq, and a channel receiving the records written
+// from now on. Call cancel to stop receiving records.
+func (rb *RingBuffer) subscribe(seq uint64) (backlog []RingEntry, ch <-chan RingEntry, cancel func()) {
+	rb.entriesMu.Lock()
+	defer rb.entriesMu.Unlock()
+	sub := make(chan RingEntry, ringSubscriberBuffer)
+	rb.subscribers[sub] = struct{}{}
+	cancel = func() {
+		rb.entriesMu.Lock()
+		delete(rb.subscribers, sub)
+		rb.entriesMu.Unlock()
+	}
+	return rb.since(seq), sub, cancel
+}
@@ -0,0 +1,176 @@
+package prettylog
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var _ http.Handler = (*RingBuffer)(nil)
+
+// ServeHTTP implements [http.Handler] interface. It serves the retained records in the format
+// given by the "format" query parameter:
+//   - text: plain text without colors.
+//   - ansi: plain text with the ANSI escape sequences, e.g. for curl in a terminal.
+//   - html: a page with the colored records, updated live.
+//   - sse: a live Server-Sent Events stream, one event per record, starting with the retained records.
+//     The "variant" query parameter selects text (default), ansi or html data.
+//     Records already received are skipped with the "since" query parameter or the Last-Event-ID
+//     header, both holding a sequence number (see [RingEntry.Seq]).
+//
+// Without format, the stream is served if the request accepts text/event-stream, the page if it
+// accepts text/html, and plain text otherwise.
+//
+// The records may contain sensitive data: mount the handler on a mux that is not publicly reachable.
+func (rb *RingBuffer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		accept := r.Header.Get("Accept")
+		switch {
+		case strings.Contains(accept, "text/event-stream"):
+			format = "sse"
+		case strings.Contains(accept, "text/html"):
+			format = "html"
+		default:
+			format = "text"
+		}
+	}
+
+	switch format {
+	case "text", "ansi":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, entry := range rb.Entries() {
+			_, _ = io.WriteString(w, ringEntryVariant(entry, format))
+		}
+	case "html":
+		rb.serveHTML(w)
+	case "sse":
+		rb.serveEvents(w, r)
+	default:
+		http.Error(w, "unknown format "+strconv.Quote(format), http.StatusBadRequest)
+	}
+}
+
+// ringEntryVariant returns the record as text, ansi or html.
+func ringEntryVariant(entry RingEntry, variant string) string {
+	switch variant {
+	case "ansi":
+		return entry.Text
+	case "html":
+		return entry.HTML()
+	default:
+		return entry.Plain()
+	}
+}
+
+const ringBufferPageHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>prettylog</title>
+<style>
+:root { --ansi-fg: #e5e5e5; --ansi-bg: #1e1e1e; }
+body { margin: 0; background: var(--ansi-bg); color: var(--ansi-fg); }
+pre { margin: 0; padding: 1em; font-family: ui-monospace, monospace; white-space: pre-wrap; }
+a { color: inherit; }
+</style>
+</head>
+<body>
+`
+
+const ringBufferPageScript = `<script>
+const logs = document.getElementById("logs");
+const source = new EventSource(location.pathname + "?format=sse&variant=html&since=" + logs.dataset.seq);
+source.onmessage = (event) => {
+  const follow = window.innerHeight + window.scrollY >= document.body.scrollHeight - 10;
+  logs.insertAdjacentHTML("beforeend", event.data + "\n");
+  if (follow) window.scrollTo(0, document.body.scrollHeight);
+};
+</script>
+</body>
+</html>
+`
+
+func (rb *RingBuffer) serveHTML(w http.ResponseWriter) {
+	entries := rb.Entries()
+	var last uint64
+	if len(entries) > 0 {
+		last = entries[len(entries)-1].Seq
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	var sb strings.Builder
+	sb.WriteString(ringBufferPageHead)
+	sb.WriteString(`<pre id="logs" data-seq="`)
+	sb.WriteString(strconv.FormatUint(last, 10))
+	sb.WriteString(`">`)
+	for _, entry := range entries {
+		sb.WriteString(entry.HTML())
+	}
+	sb.WriteString("</pre>\n")
+	sb.WriteString(ringBufferPageScript)
+	_, _ = io.WriteString(w, sb.String())
+}
+
+func (rb *RingBuffer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	variant := r.URL.Query().Get("variant")
+	since := r.URL.Query().Get("since")
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		since = id
+	}
+	seq, _ := strconv.ParseUint(since, 10, 64)
+
+	backlog, entries, cancel := rb.subscribe(seq)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	for _, entry := range backlog {
+		writeEvent(w, entry, variant)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry := <-entries:
+			writeEvent(w, entry, variant)
+			flusher.Flush()
+		}
+	}
+}
+
+// sseLineTerminators normalizes the line terminators of the event stream format, CRLF, CR and LF, to LF.
+var sseLineTerminators = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// writeEvent writes the record as a Server-Sent Event with its sequence number as id.
+func writeEvent(w io.Writer, entry RingEntry, variant string) {
+	var sb strings.Builder
+	sb.WriteString("id: ")
+	sb.WriteString(strconv.FormatUint(entry.Seq, 10))
+	sb.WriteByte('\n')
+	// Every line terminator of the event stream starts a new data field, so a record cannot
+	// inject fields like id or event.
+	data := sseLineTerminators.Replace(ringEntryVariant(entry, variant))
+	for line := range strings.SplitSeq(strings.TrimSuffix(data, "\n"), "\n") {
+		sb.WriteString("data: ")
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	sb.WriteByte('\n')
+	_, _ = io.WriteString(w, sb.String())
+}
@@ -0,0 +1,190 @@
+package prettylog
+
+import (
+	"bufio"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ringTexts(entries []RingEntry) []string {
+	texts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		texts = append(texts, entry.Text)
+	}
+	return texts
+}
+
+func TestRingBuffer(t *testing.T) {
+	ring := NewRingBuffer(3)
+	for _, record := range []string{"1\n", "2\n", "3\n", "4\n", "5\n"} {
+		if _, err := ring.Write([]byte(record)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, expected := ringTexts(ring.Entries()), []string{"3\n", "4\n", "5\n"}; !slices.Equal(got, expected) {
+		t.Errorf("expected last records %q, got %q", expected, got)
+	}
+	if got, expected := ringTexts(ring.Since(4)), []string{"5\n"}; !slices.Equal(got, expected) {
+		t.Errorf("expected records after 4 %q, got %q", expected, got)
+	}
+
+	ring.Reset()
+	ring.Write([]byte("6\n"))
+	entries := ring.Entries()
+	if len(entries) != 1 || entries[0].Seq != 6 {
+		t.Errorf("expected only the record written after reset, got %+v", entries)
+	}
+}
+
+func TestRingBufferWithHandler(t *testing.T) {
+	ring := NewRingBuffer(10)
+	logger := slog.New(New(
+		WithOutputs(Output{Writer: ring, Color: true}),
+		WithWriters(DefaultLevelWriter, DefaultMessageWriter, CompactNewLineWriter),
+	))
+	logger.Info("first")
+	logger.Warn("second")
+
+	entries := ring.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected a record per log call, got %d", len(entries))
+	}
+	if !strings.Contains(entries[0].Text, "\x1b[") {
+		t.Errorf("expected colored record, got %q", entries[0].Text)
+	}
+	if expected := " INFO  first\n"; entries[0].Plain() != expected {
+		t.Errorf("expected plain record %q, got %q", expected, entries[0].Plain())
+	}
+	if !strings.Contains(entries[1].HTML(), `<span style="`) {
+		t.Errorf("expected styled HTML, got %q", entries[1].HTML())
+	}
+}
+
+func TestRingBufferServeHTTP(t *testing.T) {
+	ring := NewRingBuffer(10)
+	ring.Write([]byte("\x1b[31mfailed\x1b[0m <op>\n"))
+	ring.Write([]byte("done\n"))
+
+	tests := []struct {
+		name        string
+		target      string
+		accept      string
+		contentType string
+		contains    []string
+	}{
+		{"text", "/?format=text", "", "text/plain", []string{"failed <op>\ndone\n"}},
+		{"ansi", "/?format=ansi", "", "text/plain", []string{"\x1b[31mfailed\x1b[0m <op>\n"}},
+		{"html", "/?format=html", "", "text/html", []string{
+			`<pre id="logs" data-seq="2">`,
+			`<span style="color:#cd0000">failed</span> &lt;op&gt;`,
+			"new EventSource(",
+		}},
+		{"negotiated html", "/", "text/html,application/xhtml+xml", "text/html", []string{`data-seq="2"`}},
+		{"negotiated text", "/", "*/*", "text/plain", []string{"failed <op>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			ring.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("expected content type %q, got %q", tt.contentType, got)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(rec.Body.String(), s) {
+					t.Errorf("expected body to contain %q, got %q", s, rec.Body.String())
+				}
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for POST, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=xml", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for unknown format, got %d", rec.Code)
+	}
+}
+
+func TestRingBufferServeEvents(t *testing.T) {
+	ring := NewRingBuffer(10)
+	ring.Write([]byte("skipped\n"))
+	ring.Write([]byte("\x1b[1mtwo\x1b[22m\nlines\n"))
+
+	server := httptest.NewServer(ring)
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"?format=sse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", got)
+	}
+
+	events := make(chan string)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event strings.Builder
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				events <- event.String()
+				event.Reset()
+				continue
+			}
+			event.WriteString(scanner.Text())
+			event.WriteByte('\n')
+		}
+	}()
+	next := func() string {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return ""
+		}
+	}
+
+	if got, expected := next(), "id: 2\ndata: two\ndata: lines\n"; got != expected {
+		t.Errorf("expected retained record %q, got %q", expected, got)
+	}
+	ring.Write([]byte("live\n"))
+	if got, expected := next(), "id: 3\ndata: live\n"; got != expected {
+		t.Errorf("expected live record %q, got %q", expected, got)
+	}
+}
+
+func TestWriteEventLineTerminators(t *testing.T) {
+	var sb strings.Builder
+	writeEvent(&sb, RingEntry{Seq: 5, Text: "failed\rid: 0\r\nevent: x\rdone\n"}, "text")
+
+	expected := "id: 5\ndata: failed\ndata: id: 0\ndata: event: x\ndata: done\n\n"
+	if got := sb.String(); got != expected {
+		t.Errorf("expected carriage returns to start new data fields %q, got %q", expected, got)
+	}
+}
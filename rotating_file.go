@@ -0,0 +1,471 @@
+package prettylog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ WriteLocker = (*RotatingFile)(nil)
+
+// RotationInterval is the period after which a [RotatingFile] is rotated, regardless of its size.
+type RotationInterval int
+
+const (
+	// RotateNever disables time based rotation.
+	RotateNever RotationInterval = iota
+	// RotateHourly rotates the file at the start of every hour.
+	RotateHourly
+	// RotateDaily rotates the file at midnight.
+	RotateDaily
+)
+
+// String implements [fmt.Stringer] interface.
+func (ri RotationInterval) String() string {
+	switch ri {
+	case RotateNever:
+		return "never"
+	case RotateHourly:
+		return "hourly"
+	case RotateDaily:
+		return "daily"
+	default:
+		return "unknown"
+	}
+}
+
+// periodStart returns the start of the rotation period t belongs to, in the location of t.
+func (ri RotationInterval) periodStart(t time.Time) time.Time {
+	switch ri {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// backupTimeFormat is the format of the rotation time in the names of backups.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile is a [WriteLocker] writing to a file that is rotated when it reaches a maximum size
+// or at the start of every hour or day. Use it with [WithOutput]:
+//
+//	file := prettylog.NewRotatingFile("/var/log/app.log").
+//	    WithMaxSize(100 << 20).
+//	    WithRotation(prettylog.RotateDaily).
+//	    WithMaxBackups(7).
+//	    WithCompression(true)
+//	defer file.Close()
+//	handler := prettylog.New(prettylog.WithOutput(file))
+//
+// Rotated files are renamed with the rotation time between the name and the extension of the file,
+// e.g. "app-2024-03-15T00-00-00.000.log", and optionally compressed with gzip in the background.
+// A counter is added if a backup with the same time exists, e.g. "app-2024-03-15T00-00-00.000.1.log".
+// Backups exceeding the maximum count or age are removed after every rotation.
+//
+// The file is opened on the first write, and its parent directories are created if needed.
+// Configure the RotatingFile before the first write. It is safe for concurrent use.
+type RotatingFile struct {
+	filename   string
+	maxSize    int64
+	rotation   RotationInterval
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+
+	// mu is the lock of the WriteLocker interface, held by the handler while writing an entry.
+	mu sync.Mutex
+
+	// fileMu guards the file, as it may be reopened or rotated outside of the lock held by writers.
+	fileMu    sync.Mutex
+	file      *os.File
+	current   atomic.Pointer[os.File]
+	size      int64
+	openedAt  time.Time
+	nextAfter time.Time
+
+	millMu sync.Mutex
+	millWG sync.WaitGroup
+
+	signals chan os.Signal
+	done    chan struct{}
+
+	now func() time.Time
+}
+
+// NewRotatingFile creates a RotatingFile writing to filename, without rotation until configured.
+func NewRotatingFile(filename string) *RotatingFile {
+	return &RotatingFile{filename: filename, now: time.Now}
+}
+
+// WithMaxSize rotates the file before a write would make it larger than size bytes.
+// A size of 0 or less disables size based rotation.
+//
+// Entries larger than size are still written whole, to a new file.
+func (r *RotatingFile) WithMaxSize(size int64) *RotatingFile {
+	r.maxSize = size
+	return r
+}
+
+// WithRotation rotates the file at the start of every period of interval, in local time.
+func (r *RotatingFile) WithRotation(interval RotationInterval) *RotatingFile {
+	r.rotation = interval
+	return r
+}
+
+// WithMaxBackups sets the number of rotated files to keep. Older files are removed.
+// A count of 0 or less keeps every backup, unless removed by the maximum age.
+func (r *RotatingFile) WithMaxBackups(count int) *RotatingFile {
+	r.maxBackups = count
+	return r
+}
+
+// WithMaxAge removes rotated files older than age, based on the time in their names.
+// An age of 0 or less keeps backups regardless of their age.
+func (r *RotatingFile) WithMaxAge(age time.Duration) *RotatingFile {
+	r.maxAge = age
+	return r
+}
+
+// WithCompression enables gzip compression of rotated files in the background.
+// Compressed backups get the ".gz" extension.
+func (r *RotatingFile) WithCompression(enabled bool) *RotatingFile {
+	r.compress = enabled
+	return r
+}
+
+// WithReopenOnSIGHUP reopens the file whenever the process receives SIGHUP, for log rotation
+// tools like logrotate, which move the file away and signal the process to create a new one.
+// It is a no-op on platforms without SIGHUP.
+//
+// The signal is handled until [RotatingFile.Close] is called.
+func (r *RotatingFile) WithReopenOnSIGHUP() *RotatingFile {
+	if len(reopenSignals) == 0 || r.signals != nil {
+		return r
+	}
+	signals, done := make(chan os.Signal, 1), make(chan struct{})
+	r.signals, r.done = signals, done
+	signal.Notify(signals, reopenSignals...)
+	go func() {
+		for {
+			select {
+			case <-signals:
+				_ = r.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// Filename returns the name of the file written to.
+func (r *RotatingFile) Filename() string {
+	return r.filename
+}
+
+// Lock implements [sync.Locker] interface.
+func (r *RotatingFile) Lock() {
+	r.mu.Lock()
+}
+
+// Unlock implements [sync.Locker] interface.
+func (r *RotatingFile) Unlock() {
+	r.mu.Unlock()
+}
+
+// Unwrap returns the file currently written to, or nil before the first write.
+// It lets [CanColor] and [DetectColorDepth] inspect the file.
+func (r *RotatingFile) Unwrap() io.Writer {
+	if f := r.current.Load(); f != nil {
+		return f
+	}
+	return nil
+}
+
+// Write implements [io.Writer] interface. The file is opened or rotated first if needed.
+func (r *RotatingFile) Write(p []byte) (n int, err error) {
+	r.fileMu.Lock()
+	defer r.fileMu.Unlock()
+
+	now := r.now()
+	if r.file == nil {
+		if err := r.open(now); err != nil {
+			return 0, err
+		}
+	}
+	if r.shouldRotate(now, int64(len(p))) {
+		if err := r.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err = r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file immediately, even if it is empty.
+func (r *RotatingFile) Rotate() error {
+	r.fileMu.Lock()
+	defer r.fileMu.Unlock()
+	now := r.now()
+	if r.file == nil {
+		if err := r.open(now); err != nil {
+			return err
+		}
+	}
+	return r.rotate(now)
+}
+
+// Reopen closes the file and opens it again by name, creating it if it was moved away.
+func (r *RotatingFile) Reopen() error {
+	r.fileMu.Lock()
+	defer r.fileMu.Unlock()
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	return r.open(r.now())
+}
+
+// Close stops handling SIGHUP, waits for the background compression and removal of backups,
+// and closes the file. The file is opened again by the next write.
+func (r *RotatingFile) Close() error {
+	if r.signals != nil {
+		signal.Stop(r.signals)
+		close(r.done)
+		r.signals = nil
+	}
+	r.fileMu.Lock()
+	err := r.closeFile()
+	r.fileMu.Unlock()
+	r.millWG.Wait()
+	return err
+}
+
+// shouldRotate reports whether writing size bytes at now requires a new file.
+func (r *RotatingFile) shouldRotate(now time.Time, size int64) bool {
+	if r.maxSize > 0 && r.size > 0 && r.size+size > r.maxSize {
+		return true
+	}
+	return !r.nextAfter.IsZero() && !now.Before(r.nextAfter)
+}
+
+// open opens the file for appending at now. A file left by a previous run in an earlier
+// rotation period is rotated when written to.
+func (r *RotatingFile) open(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(r.filename), 0o755); err != nil {
+		return fmt.Errorf("prettylog: create log directory: %w", err)
+	}
+	f, err := os.OpenFile(r.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("prettylog: open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("prettylog: stat log file: %w", err)
+	}
+	r.file = f
+	r.current.Store(f)
+	r.size = info.Size()
+	r.openedAt = now
+	if info.Size() > 0 && info.ModTime().Before(r.openedAt) {
+		r.openedAt = info.ModTime()
+	}
+	r.nextAfter = r.nextRotation(r.openedAt)
+	return nil
+}
+
+// nextRotation returns the time the file opened at t has to be rotated, or the zero time.
+func (r *RotatingFile) nextRotation(t time.Time) time.Time {
+	switch r.rotation {
+	case RotateHourly:
+		return r.rotation.periodStart(t).Add(time.Hour)
+	case RotateDaily:
+		start := r.rotation.periodStart(t)
+		return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// rotate renames the file to a backup, opens a new file and cleans up the backups in the background.
+func (r *RotatingFile) rotate(now time.Time) error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	backup := r.backupPath(now)
+	if err := os.Rename(r.filename, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("prettylog: rotate log file: %w", err)
+	}
+	if err := r.open(now); err != nil {
+		return err
+	}
+	r.millWG.Add(1)
+	go func() {
+		defer r.millWG.Done()
+		r.mill(now)
+	}()
+	return nil
+}
+
+// backupPath returns a path for a backup rotated at now that is not taken by another backup,
+// compressed or not. Rotations within the same millisecond are told apart by a counter.
+func (r *RotatingFile) backupPath(now time.Time) string {
+	ext := filepath.Ext(r.filename)
+	base := strings.TrimSuffix(r.filename, ext) + "-" + now.Format(backupTimeFormat)
+	backup := base + ext
+	for n := 1; fileExists(backup) || fileExists(backup+".gz"); n++ {
+		backup = base + "." + strconv.Itoa(n) + ext
+	}
+	return backup
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func (r *RotatingFile) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	r.current.Store(nil)
+	return err
+}
+
+// rotatedFile is a backup of a [RotatingFile].
+type rotatedFile struct {
+	path       string
+	rotatedAt  time.Time
+	counter    int
+	compressed bool
+}
+
+// mill removes the backups exceeding the maximum count and age, and compresses the others
+// if compression is enabled. Runs are serialized, so backups are not compressed twice.
+func (r *RotatingFile) mill(now time.Time) {
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	backups := r.backups()
+	var keep []rotatedFile
+	for i, backup := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.maxAge > 0 && now.Sub(backup.rotatedAt) > r.maxAge) {
+			_ = os.Remove(backup.path)
+			continue
+		}
+		keep = append(keep, backup)
+	}
+	if !r.compress {
+		return
+	}
+	for _, backup := range keep {
+		if !backup.compressed {
+			_ = compressFile(backup.path)
+		}
+	}
+}
+
+// backups returns the backups of the file, newest first.
+func (r *RotatingFile) backups() []rotatedFile {
+	dir := filepath.Dir(r.filename)
+	ext := filepath.Ext(r.filename)
+	prefix := strings.TrimSuffix(filepath.Base(r.filename), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []rotatedFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name, compressed := strings.CutSuffix(entry.Name(), ".gz")
+		stamp, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if stamp, ok = strings.CutSuffix(stamp, ext); !ok {
+			continue
+		}
+		counter := 0
+		if len(stamp) > len(backupTimeFormat) {
+			suffix, ok := strings.CutPrefix(stamp[len(backupTimeFormat):], ".")
+			n, err := strconv.Atoi(suffix)
+			if !ok || err != nil || n < 1 {
+				continue
+			}
+			stamp, counter = stamp[:len(backupTimeFormat)], n
+		}
+		rotatedAt, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, rotatedFile{
+			path:       filepath.Join(dir, entry.Name()),
+			rotatedAt:  rotatedAt,
+			counter:    counter,
+			compressed: compressed,
+		})
+	}
+	slices.SortFunc(backups, func(a, b rotatedFile) int {
+		if c := b.rotatedAt.Compare(a.rotatedAt); c != 0 {
+			return c
+		}
+		return b.counter - a.counter
+	})
+	return backups
+}
+
+// compressFile compresses the file with gzip to the same name with the ".gz" extension,
+// and removes the original file.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
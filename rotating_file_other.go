@@ -0,0 +1,8 @@
+//go:build !unix
+
+package prettylog
+
+import "os"
+
+// reopenSignals is empty, as SIGHUP is not delivered on this platform.
+var reopenSignals []os.Signal
@@ -0,0 +1,275 @@
+package prettylog
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readDir returns the names of the files in dir, sorted.
+func readDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// fakeClock returns a clock starting at start, advanced by step on every call.
+// [RotatingFile.Write] reads the clock once.
+func fakeClock(start time.Time, step time.Duration) func() time.Time {
+	now := start
+	return func() time.Time {
+		t := now
+		now = now.Add(step)
+		return t
+	}
+}
+
+func TestRotatingFileMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	file := NewRotatingFile(filepath.Join(dir, "logs", "app.log")).WithMaxSize(10)
+	file.now = fakeClock(time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local), time.Second)
+
+	for _, entry := range []string{"first\n", "second\n", "a very long entry\n"} {
+		if _, err := file.Write([]byte(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := filepath.Join(dir, "logs")
+	expected := []string{"app-2024-03-15T10-00-01.000.log", "app-2024-03-15T10-00-02.000.log", "app.log"}
+	if got := readDir(t, logs); !slices.Equal(got, expected) {
+		t.Fatalf("expected files %q, got %q", expected, got)
+	}
+	for name, content := range map[string]string{
+		expected[0]: "first\n",
+		expected[1]: "second\n",
+		expected[2]: "a very long entry\n",
+	} {
+		if got := readFile(t, filepath.Join(logs, name)); got != content {
+			t.Errorf("%s: expected %q, got %q", name, content, got)
+		}
+	}
+}
+
+func TestRotatingFileSameMillisecond(t *testing.T) {
+	dir := t.TempDir()
+	file := NewRotatingFile(filepath.Join(dir, "app.log")).WithMaxSize(1).WithMaxBackups(2)
+	file.now = fakeClock(time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local), 0)
+
+	for _, entry := range []string{"1\n", "2\n", "3\n", "4\n"} {
+		if _, err := file.Write([]byte(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first backup is removed as the oldest, the others are told apart by a counter.
+	expected := []string{"app-2024-03-15T10-00-00.000.1.log", "app-2024-03-15T10-00-00.000.2.log", "app.log"}
+	if got := readDir(t, dir); !slices.Equal(got, expected) {
+		t.Fatalf("expected files %q, got %q", expected, got)
+	}
+	for name, content := range map[string]string{
+		expected[0]: "2\n",
+		expected[1]: "3\n",
+		expected[2]: "4\n",
+	} {
+		if got := readFile(t, filepath.Join(dir, name)); got != content {
+			t.Errorf("%s: expected %q, got %q", name, content, got)
+		}
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	file := NewRotatingFile(filename).WithRotation(RotateDaily)
+	file.now = fakeClock(time.Date(2024, 3, 15, 23, 59, 59, 0, time.Local), 500*time.Millisecond)
+
+	for _, entry := range []string{"day 1\n", "day 1 again\n", "day 2\n"} {
+		if _, err := file.Write([]byte(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"app-2024-03-16T00-00-00.000.log", "app.log"}
+	if got := readDir(t, dir); !slices.Equal(got, expected) {
+		t.Fatalf("expected files %q, got %q", expected, got)
+	}
+	if got := readFile(t, filepath.Join(dir, expected[0])); got != "day 1\nday 1 again\n" {
+		t.Errorf("expected records of the first day in the backup, got %q", got)
+	}
+	if got := readFile(t, filename); got != "day 2\n" {
+		t.Errorf("expected records of the second day in the file, got %q", got)
+	}
+}
+
+func TestRotatingFileRotatesStaleFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(filename, []byte("previous run\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(filename, yesterday, yesterday); err != nil {
+		t.Fatal(err)
+	}
+
+	file := NewRotatingFile(filename).WithRotation(RotateHourly)
+	if _, err := file.Write([]byte("this run\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filename); got != "this run\n" {
+		t.Errorf("expected the file of the previous run to be rotated, got %q", got)
+	}
+	if got := len(readDir(t, dir)); got != 2 {
+		t.Errorf("expected a backup of the previous run, got %d files", got)
+	}
+}
+
+func TestRotatingFileRetentionAndCompression(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	start := time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local)
+
+	// Backups left by previous runs: one too old, one within the age limit.
+	for _, stamp := range []time.Time{start.Add(-48 * time.Hour), start.Add(-time.Hour)} {
+		backup := filepath.Join(dir, "app-"+stamp.Format(backupTimeFormat)+".log")
+		if err := os.WriteFile(backup, []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.log"), []byte("unrelated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := NewRotatingFile(filename).
+		WithMaxSize(1).
+		WithMaxBackups(2).
+		WithMaxAge(24 * time.Hour).
+		WithCompression(true)
+	file.now = fakeClock(start, time.Minute)
+	for _, entry := range []string{"1\n", "2\n", "3\n"} {
+		if _, err := file.Write([]byte(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"app-2024-03-15T10-01-00.000.log.gz",
+		"app-2024-03-15T10-02-00.000.log.gz",
+		"app.log",
+		"other.log",
+	}
+	if got := readDir(t, dir); !slices.Equal(got, expected) {
+		t.Fatalf("expected files %q, got %q", expected, got)
+	}
+
+	f, err := os.Open(filepath.Join(dir, expected[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "2\n" {
+		t.Errorf("expected compressed backup content %q, got %q", "2\n", content)
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	file := NewRotatingFile(filename)
+	defer file.Close()
+
+	if _, err := file.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	// Move the file away like logrotate does.
+	moved := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(filename, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, moved); got != "before\n" {
+		t.Errorf("expected moved file to keep previous records, got %q", got)
+	}
+	if got := readFile(t, filename); got != "after\n" {
+		t.Errorf("expected records after reopen in a new file, got %q", got)
+	}
+}
+
+func TestRotatingFileWithHandler(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	file := NewRotatingFile(filename)
+	defer file.Close()
+
+	if WrapWriteLocker(file) != WriteLocker(file) {
+		t.Error("expected RotatingFile to be used as is by WrapWriteLocker")
+	}
+	if file.Unwrap() != nil {
+		t.Error("expected no file before the first write")
+	}
+
+	logger := slog.New(New(WithOutput(file), WithColor(false), WithWriters(DefaultMessageWriter, DefaultNewLineWriter)))
+	logger.Info("hello")
+
+	if _, ok := fdOf(file); !ok {
+		t.Error("expected the file descriptor to be found through Unwrap")
+	}
+	if CanColor(file) {
+		t.Error("expected a regular file not to support colors")
+	}
+	if got := readFile(t, filename); !strings.Contains(got, "hello") {
+		t.Errorf("expected record in file, got %q", got)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package prettylog
+
+import (
+	"os"
+	"syscall"
+)
+
+// reopenSignals are the signals reopening a [RotatingFile] set up with [RotatingFile.WithReopenOnSIGHUP].
+var reopenSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package prettylog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileReopenOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	file := NewRotatingFile(filename).WithReopenOnSIGHUP()
+	defer file.Close()
+
+	if _, err := file.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filename, filepath.Join(dir, "app.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filename); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the file to be reopened on SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := file.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filename); got != "after\n" {
+		t.Errorf("expected records after SIGHUP in a new file, got %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+package prettylog
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ModuleFileLineFormat returns the file path and line number of the caller relative to the
+// Go module it belongs to, independently of the working directory:
+//   - Main module: path relative to the module root, e.g. "internal/server/server.go:42".
+//   - Dependencies: "module@version/path.go:12", e.g. "github.com/lib/pq@v1.10.9/conn.go:12".
+//   - Standard library: "std@goversion/path.go:12", e.g. "std@go1.24.5/net/http/server.go:12".
+//
+// Modules are resolved from the package path of the function using [debug.ReadBuildInfo],
+// falling back to the GOMODCACHE and GOROOT prefixes of the file path. If the module cannot
+// be resolved, the full file path is returned.
+//
+// Results are cached per call site by the handler, see [WithFrameCacheSize].
+func ModuleFileLineFormat(info RecordData) string {
+	if !hasCaller(info.Frame) {
+		return ""
+	}
+	return cachedFrameFormat(info, formatModuleFileLine, moduleFileLine)
+}
+
+func moduleFileLine(info RecordData) string {
+	return modulePath(info.Frame.Function, info.Frame.File) + ":" + strconv.Itoa(info.Frame.Line)
+}
+
+// modulePath returns the path of file relative to its module, prefixed by the module path and
+// version for files outside of the main module. See [ModuleFileLineFormat].
+func modulePath(function, file string) string {
+	base := path.Base(filepath.ToSlash(file))
+	if function != "" {
+		if rel := mainModuleRelativePath(function, file); rel != "" {
+			return rel
+		}
+		pkg := packagePath(function)
+		if bi, ok := readBuildInfo(); ok {
+			var best *debug.Module
+			for _, dep := range bi.Deps {
+				if isPathPrefix(pkg, dep.Path) && (best == nil || len(dep.Path) > len(best.Path)) {
+					best = dep
+				}
+			}
+			if best != nil {
+				version := best.Version
+				if best.Replace != nil && best.Replace.Version != "" {
+					version = best.Replace.Version
+				}
+				return path.Join(best.Path+"@"+version, strings.TrimPrefix(pkg, best.Path), base)
+			}
+		}
+		if isStandardPackage(pkg) {
+			return path.Join("std@"+runtime.Version(), pkg, base)
+		}
+	}
+
+	slashed := filepath.ToSlash(file)
+	if root := goModCache(); root != "" {
+		if rel, ok := strings.CutPrefix(slashed, root+"/"); ok {
+			return rel
+		}
+	}
+	if root := goRootSrc(); root != "" {
+		if rel, ok := strings.CutPrefix(slashed, root+"/"); ok {
+			return "std@" + runtime.Version() + "/" + rel
+		}
+	}
+	return file
+}
+
+var readBuildInfo = sync.OnceValues(debug.ReadBuildInfo)
+
+// mainModuleRelativePath returns the path of file relative to the main module root,
+// using the package path of function. It returns an empty string if function is not
+// part of the main module.
+func mainModuleRelativePath(function, file string) string {
+	bi, ok := readBuildInfo()
+	if !ok || bi.Main.Path == "" || file == "" {
+		return ""
+	}
+	pkg := packagePath(function)
+	if pkg == "main" {
+		pkg = bi.Path
+	}
+	dir, ok := strings.CutPrefix(pkg, bi.Main.Path)
+	if !ok || (dir != "" && dir[0] != '/') {
+		return ""
+	}
+	return path.Join(strings.TrimPrefix(dir, "/"), path.Base(filepath.ToSlash(file)))
+}
+
+// packagePath returns the import path of the package of a function name
+// as reported by [runtime.Frame.Function], e.g. "github.com/user/repo/pkg"
+// for "github.com/user/repo/pkg.(*Type).Method".
+//
+// The runtime escapes dots in the last element of the import path as "%2e",
+// e.g. "gopkg.in/yaml%2ev3.Unmarshal", so the first dot after the last slash
+// always separates the package path from the function name.
+func packagePath(function string) string {
+	lastSlash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[lastSlash+1:], '.')
+	if dot != -1 {
+		function = function[:lastSlash+1+dot]
+	}
+	return strings.ReplaceAll(function, "%2e", ".")
+}
+
+// isPathPrefix reports whether pkg is the import path prefix or a package inside it.
+func isPathPrefix(pkg, prefix string) bool {
+	rest, ok := strings.CutPrefix(pkg, prefix)
+	return ok && (rest == "" || rest[0] == '/')
+}
+
+// isStandardPackage reports whether pkg is part of the standard library,
+// whose import paths have no dot in their first element.
+func isStandardPackage(pkg string) bool {
+	first, _, _ := strings.Cut(pkg, "/")
+	return pkg != "main" && !strings.Contains(first, ".")
+}
+
+// goModCache returns the module cache directory with forward slashes, using the same defaults as the go command.
+var goModCache = sync.OnceValue(func() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return filepath.ToSlash(dir)
+	}
+	gopath, _, _ := strings.Cut(os.Getenv("GOPATH"), string(filepath.ListSeparator))
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		gopath = filepath.Join(home, "go")
+	}
+	return filepath.ToSlash(filepath.Join(gopath, "pkg", "mod"))
+})
+
+// goRootSrc returns the source directory of the standard library the binary was built with,
+// found from the file of a standard library function. It is empty if the path is trimmed.
+var goRootSrc = sync.OnceValue(func() string {
+	fn := runtime.FuncForPC(reflect.ValueOf(strings.Cut).Pointer())
+	if fn == nil {
+		return ""
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	root, ok := strings.CutSuffix(filepath.ToSlash(file), "/strings/strings.go")
+	if !ok || !path.IsAbs(root) && !filepath.IsAbs(root) {
+		return ""
+	}
+	return root
+})
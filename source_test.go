@@ -0,0 +1,73 @@
+package prettylog
+
+import (
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestModulePath(t *testing.T) {
+	withBuildInfo(t, &debug.BuildInfo{
+		Path: "github.com/user/app/cmd/server",
+		Main: debug.Module{Path: "github.com/user/app", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/lib/pq", Version: "v1.10.9"},
+			{Path: "github.com/lib/pq/extra", Version: "v0.2.0"},
+			{Path: "example.com/forked", Version: "v1.0.0", Replace: &debug.Module{Path: "example.com/fork", Version: "v1.0.1"}},
+		},
+	})
+	originalModCache := goModCache
+	goModCache = func() string { return "/cache/mod" }
+	t.Cleanup(func() { goModCache = originalModCache })
+
+	tests := []struct {
+		name     string
+		function string
+		file     string
+		expected string
+	}{
+		{"main module", "github.com/user/app/internal/db.Open", "/src/app/internal/db/db.go", "internal/db/db.go"},
+		{"main package", "main.main", "/src/app/cmd/server/main.go", "cmd/server/main.go"},
+		{"module root", "github.com/user/app.Run", "/src/app/app.go", "app.go"},
+		{"dependency", "github.com/lib/pq.(*conn).query", "/cache/mod/github.com/lib/pq@v1.10.9/conn.go", "github.com/lib/pq@v1.10.9/conn.go"},
+		{"nested module", "github.com/lib/pq/extra/sub.Do", "/x/sub/do.go", "github.com/lib/pq/extra@v0.2.0/sub/do.go"},
+		{"replaced dependency", "example.com/forked/pkg.Do", "/x/pkg/do.go", "example.com/forked@v1.0.1/pkg/do.go"},
+		{"standard library", "net/http.(*conn).serve", "/usr/lib/go/src/net/http/server.go", "std@" + runtime.Version() + "/net/http/server.go"},
+		{"module cache fallback", "", "/cache/mod/golang.org/x/sys@v0.25.0/unix/ioctl.go", "golang.org/x/sys@v0.25.0/unix/ioctl.go"},
+		{"unknown", "", "/elsewhere/file.go", "/elsewhere/file.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modulePath(tt.function, tt.file); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestModuleFileLineFormat(t *testing.T) {
+	pc, _, line, _ := runtime.Caller(0)
+	frame, entry := newFrameCache(1).lookup(pc)
+	info := RecordData{
+		Record:     slog.NewRecord(time.Now(), slog.LevelInfo, "message", pc),
+		Frame:      frame,
+		frameEntry: entry,
+	}
+
+	expected := "source_test.go:" + strconv.Itoa(line)
+	if got := ModuleFileLineFormat(info); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if entry.formatted[formatModuleFileLine].Load() == nil {
+		t.Error("expected result to be cached with the frame")
+	}
+	if got := ModuleFileLineFormat(info); got != expected {
+		t.Errorf("expected cached %q, got %q", expected, got)
+	}
+	if got := ModuleFileLineFormat(RecordData{}); got != "" {
+		t.Errorf("expected empty string without caller, got %q", got)
+	}
+}
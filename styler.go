@@ -1,26 +1,74 @@
 package prettylog
 
 import (
-	"log/slog"
-
-	"github.com/fatih/color"
+	"bytes"
+	"sync"
 )
 
 // Styler is a function type that applies styling (colors, formatting) to formatted strings.
 // It receives the RecordData for context (like log level) and the formatted string,
 // returning the styled version.
+//
+// Stylers allocate the styled string on every call. Prefer [StyleWriter] for custom
+// styling on the hot path.
 type Styler func(info RecordData, formatted string) (styled string)
 
+// StyleWriter is the variant of [Styler] writing the styled string directly to buf,
+// without allocating.
+//
+// The built-in style writers use escape sequences computed once per style and color depth.
+type StyleWriter func(info RecordData, buf *bytes.Buffer, formatted string)
+
+// StyleWriter returns a [StyleWriter] writing the result of s.
+func (s Styler) StyleWriter() StyleWriter {
+	return func(info RecordData, buf *bytes.Buffer, formatted string) {
+		buf.WriteString(s(info, formatted))
+	}
+}
+
+// Styler returns a [Styler] returning what sw writes, for APIs taking the string form.
+func (sw StyleWriter) Styler() Styler {
+	return func(info RecordData, formatted string) string {
+		return styleString(sw, info, formatted)
+	}
+}
+
+// styleBufferPool holds the buffers used to style strings and measure styled keys.
+var styleBufferPool = sync.Pool{
+	New: func() any { return &bytes.Buffer{} },
+}
+
+// styleString returns what sw writes for s.
+func styleString(sw StyleWriter, info RecordData, s string) string {
+	buf := styleBufferPool.Get().(*bytes.Buffer)
+	sw(info, buf, s)
+	styled := buf.String()
+	buf.Reset()
+	styleBufferPool.Put(buf)
+	return styled
+}
+
 // SimpleColoredStyler applies color styling based on the log level.
-// Colors: Error=Red, Warn=Yellow, Info=Green, Debug=Cyan, others=White.
+// Colors are taken from [RecordData.Levels] for custom level styles and [RecordData.Theme] otherwise.
+// Default colors: Error=Red, Warn=Yellow, Info=Green, Debug=Cyan, others=White.
 func SimpleColoredStyler(info RecordData, s string) string {
-	return getColoredText(info.Record.Level).Sprint(s)
+	return styleString(SimpleColoredStyleWriter, info, s)
+}
+
+// SimpleColoredStyleWriter is the [StyleWriter] variant of [SimpleColoredStyler].
+func SimpleColoredStyleWriter(info RecordData, buf *bytes.Buffer, s string) {
+	ansiSequenceOf(info, levelStyleOf(info).Text, false).write(buf, s)
 }
 
 // BoldColoredStyler applies bold color styling based on the log level.
 // Same colors as SimpleColoredStyler but with bold formatting.
 func BoldColoredStyler(info RecordData, s string) string {
-	return getColoredText(info.Record.Level).Add(color.Bold).Sprint(s)
+	return styleString(BoldColoredStyleWriter, info, s)
+}
+
+// BoldColoredStyleWriter is the [StyleWriter] variant of [BoldColoredStyler].
+func BoldColoredStyleWriter(info RecordData, buf *bytes.Buffer, s string) {
+	ansiSequenceOf(info, levelStyleOf(info).Text, true).write(buf, s)
 }
 
 // PlainStyler returns the string unchanged without any styling.
@@ -29,42 +77,68 @@ func PlainStyler(info RecordData, s string) string {
 	return s
 }
 
+// PlainStyleWriter is the [StyleWriter] variant of [PlainStyler].
+func PlainStyleWriter(info RecordData, buf *bytes.Buffer, s string) {
+	buf.WriteString(s)
+}
+
 // BackgroundBoldColoredStyler applies background color with bold white text based on log level.
 // Provides high contrast styling suitable for important information like log levels.
 func BackgroundBoldColoredStyler(info RecordData, s string) string {
-	return getColoredBackground(info.Record.Level).Add(color.Bold).Sprintf(" %s ", s)
+	return styleString(BackgroundBoldColoredStyleWriter, info, s)
 }
 
-// getColoredText returns a color.Color with text color based on the log level.
-//
-// Should be used with transparent background color.
-func getColoredText(lvl slog.Level) *color.Color {
-	switch {
-	case lvl >= slog.LevelError:
-		return color.New(color.FgRed)
-	case lvl >= slog.LevelWarn:
-		return color.New(color.FgYellow)
-	case lvl >= slog.LevelInfo:
-		return color.New(color.FgGreen)
-	case lvl >= slog.LevelDebug:
-		return color.New(color.FgCyan)
-	default:
-		return color.New(color.FgWhite)
+// BackgroundBoldColoredStyleWriter is the [StyleWriter] variant of [BackgroundBoldColoredStyler].
+func BackgroundBoldColoredStyleWriter(info RecordData, buf *bytes.Buffer, s string) {
+	seq := ansiSequenceOf(info, levelStyleOf(info).Background, true)
+	buf.WriteString(seq.prefix)
+	buf.WriteByte(' ')
+	buf.WriteString(s)
+	buf.WriteByte(' ')
+	buf.WriteString(seq.suffix)
+}
+
+// KeyColoredStyler applies the key style of [RecordData.Theme].
+// If the theme has no key style, it behaves like [BoldColoredStyler].
+func KeyColoredStyler(info RecordData, s string) string {
+	return styleString(KeyColoredStyleWriter, info, s)
+}
+
+// KeyColoredStyleWriter is the [StyleWriter] variant of [KeyColoredStyler].
+func KeyColoredStyleWriter(info RecordData, buf *bytes.Buffer, s string) {
+	theme := themeOf(info)
+	if len(theme.Key) == 0 {
+		BoldColoredStyleWriter(info, buf, s)
+		return
 	}
+	ansiSequenceOf(info, theme.Key, false).write(buf, s)
+}
+
+// TimeStyler applies the time style of [RecordData.Theme].
+func TimeStyler(info RecordData, s string) string {
+	return styleString(TimeStyleWriter, info, s)
+}
+
+// TimeStyleWriter is the [StyleWriter] variant of [TimeStyler].
+func TimeStyleWriter(info RecordData, buf *bytes.Buffer, s string) {
+	writeStyle(info, buf, themeOf(info).Time, s)
+}
+
+// SourceStyler applies the source style of [RecordData.Theme].
+// It is used for function names and file paths.
+func SourceStyler(info RecordData, s string) string {
+	return styleString(SourceStyleWriter, info, s)
+}
+
+// SourceStyleWriter is the [StyleWriter] variant of [SourceStyler].
+func SourceStyleWriter(info RecordData, buf *bytes.Buffer, s string) {
+	writeStyle(info, buf, themeOf(info).Source, s)
 }
 
-// getColoredBackground returns a color.Color with background color based on the log level and contrasting text color.
-func getColoredBackground(lvl slog.Level) *color.Color {
-	switch {
-	case lvl >= slog.LevelError:
-		return color.New(color.BgRed, color.FgWhite)
-	case lvl >= slog.LevelWarn:
-		return color.New(color.BgYellow, color.FgWhite)
-	case lvl >= slog.LevelInfo:
-		return color.New(color.BgGreen, color.FgWhite)
-	case lvl >= slog.LevelDebug:
-		return color.New(color.BgCyan, color.FgWhite)
-	default:
-		return color.New(color.BgWhite, color.FgBlack)
+func writeStyle(info RecordData, buf *bytes.Buffer, style Style, s string) {
+	if len(style) == 0 {
+		buf.WriteString(s)
+		return
 	}
+	ansiSequenceOf(info, style, false).write(buf, s)
 }
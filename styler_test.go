@@ -0,0 +1,221 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestStyleWritersMatchFatihColor(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	theme := &Theme{
+		Info:   LevelStyle{Text: Style{38, 2, 255, 135, 0, color.Italic}, Background: Style{48, 5, 208, color.FgBlack}},
+		Key:    Style{color.FgHiBlue, color.Underline},
+		Time:   Style{color.Faint},
+		Source: Style{color.CrossedOut, color.BlinkSlow},
+	}
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+	fatih := func(depth ColorDepth, style Style, bold bool, s string) string {
+		c := color.New(style.Degrade(depth)...)
+		if bold {
+			c.Add(color.Bold)
+		}
+		return c.Sprint(s)
+	}
+
+	for _, depth := range []ColorDepth{Color16, Color256, ColorTrueColor} {
+		info := RecordData{Record: record, Theme: theme, ColorDepth: depth}
+		tests := []struct {
+			name     string
+			sw       StyleWriter
+			styler   Styler
+			expected string
+		}{
+			{"simple", SimpleColoredStyleWriter, SimpleColoredStyler, fatih(depth, theme.Info.Text, false, "text")},
+			{"bold", BoldColoredStyleWriter, BoldColoredStyler, fatih(depth, theme.Info.Text, true, "text")},
+			{"background", BackgroundBoldColoredStyleWriter, BackgroundBoldColoredStyler, fatih(depth, theme.Info.Background, true, " text ")},
+			{"key", KeyColoredStyleWriter, KeyColoredStyler, fatih(depth, theme.Key, false, "text")},
+			{"time", TimeStyleWriter, TimeStyler, fatih(depth, theme.Time, false, "text")},
+			{"source", SourceStyleWriter, SourceStyler, fatih(depth, theme.Source, false, "text")},
+			{"plain", PlainStyleWriter, PlainStyler, "text"},
+		}
+		for _, tt := range tests {
+			t.Run(depth.String()+"/"+tt.name, func(t *testing.T) {
+				buf := &bytes.Buffer{}
+				tt.sw(info, buf, "text")
+				if got := buf.String(); got != tt.expected {
+					t.Errorf("style writer: expected %q, got %q", tt.expected, got)
+				}
+				if got := tt.styler(info, "text"); got != tt.expected {
+					t.Errorf("styler: expected %q, got %q", tt.expected, got)
+				}
+				if got := tt.sw.Styler()(info, "text"); got != tt.expected {
+					t.Errorf("style writer adapter: expected %q, got %q", tt.expected, got)
+				}
+				buf.Reset()
+				tt.styler.StyleWriter()(info, buf, "text")
+				if got := buf.String(); got != tt.expected {
+					t.Errorf("styler adapter: expected %q, got %q", tt.expected, got)
+				}
+			})
+		}
+	}
+
+	if len(theme.Info.Text) != 6 || cap(theme.Info.Text) != 6 {
+		t.Errorf("expected theme style to be left untouched, got %v", theme.Info.Text)
+	}
+}
+
+func TestCommonWriterStylerPrecedence(t *testing.T) {
+	upper := func(info RecordData, s string) string { return strings.ToUpper(s) }
+	brackets := func(info RecordData, buf *bytes.Buffer, s string) {
+		buf.WriteByte('[')
+		buf.WriteString(s)
+		buf.WriteByte(']')
+	}
+	info := RecordData{
+		Record: slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0),
+		Color:  true,
+		Layout: CompactLayout,
+	}
+
+	tests := []struct {
+		name     string
+		writer   *CommonWriter
+		expected string
+	}{
+		{"style writer", NewCommonWriter(DefaultMessageValuer).WithValueStyleWriter(brackets), "[message]"},
+		{"styler first", NewCommonWriter(DefaultMessageValuer).WithValueStyleWriter(brackets).WithValueColorizer(upper), "MESSAGE"},
+		{"style writer replaces styler", NewCommonWriter(DefaultMessageValuer).WithValueColorizer(upper).WithValueStyleWriter(brackets), "[message]"},
+		{"no styling", &CommonWriter{Key: Static(""), Valuer: DefaultMessageValuer, Prefix: DefaultPrefix}, "message"},
+		{"struct literal styler", &CommonWriter{Key: Static(""), Valuer: DefaultMessageValuer, Prefix: DefaultPrefix, ValueStyler: upper}, "MESSAGE"},
+		{"assigned style writer", func() *CommonWriter {
+			cw := NewCommonWriter(DefaultMessageValuer)
+			cw.ValueStyleWriter = brackets
+			return cw
+		}(), "[message]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			info := info
+			info.Buffer = buf
+			tt.writer.Write(info)
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCommonWriterDefaultStylers(t *testing.T) {
+	info := benchmarkStyleRecordData()
+	cw := NewCommonWriter(DefaultMessageValuer)
+	if cw.KeyStyler == nil || cw.ValueStyler == nil {
+		t.Fatal("expected key and value stylers to be set")
+	}
+	if got, expected := cw.KeyStyler(info, "key"), KeyColoredStyler(info, "key"); got != expected {
+		t.Errorf("expected key styler to adapt the key style writer %q, got %q", expected, got)
+	}
+	if got := cw.ValueStyler(info, "value"); got != "value" {
+		t.Errorf("expected plain value styler, got %q", got)
+	}
+
+	cw.WithValueStyleWriter(BoldColoredStyleWriter)
+	if got, expected := cw.ValueStyler(info, "value"), BoldColoredStyler(info, "value"); got != expected {
+		t.Errorf("expected value styler to follow the value style writer %q, got %q", expected, got)
+	}
+	wrapped := cw.ValueStyler
+	cw.WithValueColorizer(func(info RecordData, s string) string { return "<" + wrapped(info, s) + ">" })
+	cw.Write(info)
+	if got, expected := info.Buffer.String(), "<"+BoldColoredStyler(info, "message")+">"; got != expected {
+		t.Errorf("expected wrapped default styler %q, got %q", expected, got)
+	}
+}
+
+func TestCommonWriterStyleWriterAllocations(t *testing.T) {
+	for _, alignment := range []KeyAlignment{KeyAlignLeft, KeyAlignRight, KeyAlignFixed} {
+		info := benchmarkStyleRecordData()
+		info.KeyAlignment = alignment
+		writer := NewCommonWriter(DefaultMessageValuer).WithStaticKey("Message").
+			WithValueStyleWriter(SimpleColoredStyleWriter)
+		writer.Write(info) // Warm up the sequence cache and the buffer.
+
+		allocs := testing.AllocsPerRun(100, func() {
+			info.Buffer.Reset()
+			info.KeyFieldLength = writer.KeyLen(info) + 2
+			writer.Write(info)
+		})
+		if allocs != 0 {
+			t.Errorf("alignment %d: expected no allocations, got %v", alignment, allocs)
+		}
+	}
+}
+
+func benchmarkStyleRecordData() RecordData {
+	return RecordData{
+		Record:     slog.NewRecord(time.Now(), slog.LevelWarn, "message", 0),
+		Color:      true,
+		ColorDepth: ColorTrueColor,
+		Theme:      DarkTheme,
+		Buffer:     &bytes.Buffer{},
+	}
+}
+
+func BenchmarkStyleWriter(b *testing.B) {
+	for _, tt := range []struct {
+		name   string
+		sw     StyleWriter
+		styler Styler
+	}{
+		{"simple", SimpleColoredStyleWriter, SimpleColoredStyler},
+		{"background", BackgroundBoldColoredStyleWriter, BackgroundBoldColoredStyler},
+		{"key", KeyColoredStyleWriter, KeyColoredStyler},
+	} {
+		info := benchmarkStyleRecordData()
+		b.Run(tt.name+"/style-writer", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				info.Buffer.Reset()
+				tt.sw(info, info.Buffer, "message")
+			}
+		})
+		b.Run(tt.name+"/styler", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				info.Buffer.Reset()
+				info.Buffer.WriteString(tt.styler(info, "message"))
+			}
+		})
+	}
+}
+
+func BenchmarkCommonWriter(b *testing.B) {
+	info := benchmarkStyleRecordData()
+	info.KeyFieldLength = 10
+	b.Run("style-writer", func(b *testing.B) {
+		writer := NewCommonWriter(DefaultMessageValuer).WithStaticKey("Message").
+			WithValueStyleWriter(SimpleColoredStyleWriter)
+		b.ReportAllocs()
+		for b.Loop() {
+			info.Buffer.Reset()
+			writer.Write(info)
+		}
+	})
+	b.Run("styler", func(b *testing.B) {
+		writer := NewCommonWriter(DefaultMessageValuer).WithStaticKey("Message").
+			WithKeyColorizer(KeyColoredStyler).WithValueColorizer(SimpleColoredStyler)
+		b.ReportAllocs()
+		for b.Loop() {
+			info.Buffer.Reset()
+			writer.Write(info)
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package prettylog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var _ EntryWriter = (*TemplateWriter)(nil)
+
+// TemplatePlaceholder creates the [EntryWriter] for a template placeholder.
+//
+// arg is the text after the colon in the placeholder (e.g. "RFC3339" for `{time:RFC3339}`),
+// or an empty string if there is none. Returning an error fails the template parsing.
+//
+// The returned writer receives an empty [RecordData.Buffer], so it must not write any
+// separator before its output.
+type TemplatePlaceholder func(arg string) (EntryWriter, error)
+
+// TemplatePlaceholders are the placeholders known to [ParseTemplate].
+//
+// Built-in placeholders:
+//
+//   - {time}, {time:<layout>}: Timestamp. Layout is one of TimeOnly (default), DateTime, DateOnly,
+//     RFC3339, RFC3339Nano, Kitchen, Stamp, StampMilli, StampMicro, StampNano, elapsed (see [ElapsedTimeFormat]),
+//     delta (see [DeltaTimeFormat]), humanized (see [HumanizedTimeFormat]), or a custom Go time layout.
+//   - {level}, {level:<style>}: Log level. Style is one of color (default), bold, bg or plain.
+//   - {message}, {message:<style>}: Log message. Style is one of color (default) or plain. Alias: {msg}.
+//   - {func}, {func:<format>}: Caller function. Format is short (default) or long.
+//   - {source}, {source:<format>}: Caller file and line. Format is short (default), long or module
+//     (see [ModuleFileLineFormat]).
+//   - {attrs}, {attrs:<format>}: Log attributes. Format is json (default) or logfmt.
+//
+// Custom placeholders can be added to this map before calling [ParseTemplate].
+var TemplatePlaceholders = map[string]TemplatePlaceholder{
+	"time":    timePlaceholder,
+	"level":   levelPlaceholder,
+	"message": messagePlaceholder,
+	"msg":     messagePlaceholder,
+	"func":    funcPlaceholder,
+	"source":  sourcePlaceholder,
+	"attrs":   attrsPlaceholder,
+}
+
+// TemplateWriter is an entry writer that renders log entries from a template.
+//
+// Use [ParseTemplate] to create one, or [WithTemplate] to set it as the only writer of a [Handler].
+//
+// Template syntax:
+//
+//   - `{name}` or `{name:arg}` is replaced by the output of the placeholder. See [TemplatePlaceholders].
+//   - `{?...}` is a conditional section. The section is removed entirely if none of the placeholders
+//     inside it produced output, e.g. `{? at {source}}` is removed when caller information is not available.
+//   - `{{` and `}}` are literal braces.
+//   - Everything else is written as is.
+//
+// A new line is added at the end of the entry if the rendered entry does not end with one.
+type TemplateWriter struct {
+	text  string
+	nodes []templateNode
+
+	pool *limitedPool
+}
+
+// ParseTemplate compiles the template text into a [TemplateWriter].
+//
+// An error is returned if the template is malformed or uses an unknown placeholder or argument.
+func ParseTemplate(text string) (*TemplateWriter, error) {
+	nodes, _, err := parseTemplate(text, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateWriter{
+		text:  text,
+		nodes: nodes,
+		pool:  newLimitedPool(16 * 1024), // 16KB
+	}, nil
+}
+
+// MustParseTemplate is like [ParseTemplate] but panics if the template cannot be parsed.
+func MustParseTemplate(text string) *TemplateWriter {
+	tw, err := ParseTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return tw
+}
+
+// String returns the template text.
+func (tw *TemplateWriter) String() string {
+	return tw.text
+}
+
+// KeyLen implements [EntryWriter] interface. Always returns 0.
+func (tw *TemplateWriter) KeyLen(info RecordData) int {
+	return 0
+}
+
+// Write implements [EntryWriter] interface.
+func (tw *TemplateWriter) Write(info RecordData) {
+	scratch := tw.pool.Get()
+	defer tw.pool.Put(scratch)
+
+	start := info.Buffer.Len()
+	renderTemplate(tw.nodes, info, scratch)
+	if b := info.Buffer.Bytes(); len(b) > start && b[len(b)-1] != '\n' {
+		info.Buffer.WriteByte('\n')
+	}
+}
+
+type templateNode struct {
+	literal string
+	writer  EntryWriter
+	section []templateNode
+}
+
+// renderTemplate writes the nodes into info.Buffer and reports whether any placeholder
+// produced output.
+func renderTemplate(nodes []templateNode, info RecordData, scratch *bytes.Buffer) (wrote bool) {
+	for _, node := range nodes {
+		switch {
+		case node.writer != nil:
+			scratch.Reset()
+			sub := info
+			sub.Buffer = scratch
+			node.writer.Write(sub)
+			out := bytes.TrimRight(scratch.Bytes(), "\n")
+			if len(out) > 0 {
+				wrote = true
+				info.Buffer.Write(out)
+			}
+		case node.section != nil:
+			mark := info.Buffer.Len()
+			if renderTemplate(node.section, info, scratch) {
+				wrote = true
+			} else {
+				info.Buffer.Truncate(mark)
+			}
+		default:
+			info.Buffer.WriteString(node.literal)
+		}
+	}
+	return wrote
+}
+
+// parseTemplate parses text starting from pos until the end of text or, if inSection is true,
+// until the closing brace of the section. It returns the position after the last consumed byte.
+func parseTemplate(text string, pos int, inSection bool) ([]templateNode, int, error) {
+	var (
+		nodes   []templateNode
+		literal strings.Builder
+	)
+	flush := func() {
+		if literal.Len() > 0 {
+			nodes = append(nodes, templateNode{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+	for pos < len(text) {
+		c := text[pos]
+		switch {
+		case c == '{' && strings.HasPrefix(text[pos:], "{{"):
+			literal.WriteByte('{')
+			pos += 2
+		case c == '}' && strings.HasPrefix(text[pos:], "}}"):
+			literal.WriteByte('}')
+			pos += 2
+		case c == '}':
+			if !inSection {
+				return nil, pos, fmt.Errorf("prettylog: template: unexpected '}' at offset %d", pos)
+			}
+			flush()
+			return nodes, pos + 1, nil
+		case c == '{' && strings.HasPrefix(text[pos:], "{?"):
+			flush()
+			section, end, err := parseTemplate(text, pos+2, true)
+			if err != nil {
+				return nil, end, err
+			}
+			if len(section) > 0 {
+				nodes = append(nodes, templateNode{section: section})
+			}
+			pos = end
+		case c == '{':
+			flush()
+			end := strings.IndexAny(text[pos+1:], "{}")
+			if end == -1 || text[pos+1+end] != '}' {
+				return nil, pos, fmt.Errorf("prettylog: template: unterminated placeholder at offset %d", pos)
+			}
+			writer, err := compilePlaceholder(text[pos+1 : pos+1+end])
+			if err != nil {
+				return nil, pos, fmt.Errorf("prettylog: template: placeholder at offset %d: %w", pos, err)
+			}
+			nodes = append(nodes, templateNode{writer: writer})
+			pos += end + 2
+		default:
+			literal.WriteByte(c)
+			pos++
+		}
+	}
+	if inSection {
+		return nil, pos, fmt.Errorf("prettylog: template: unterminated section")
+	}
+	flush()
+	return nodes, pos, nil
+}
+
+func compilePlaceholder(spec string) (EntryWriter, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	placeholder, ok := TemplatePlaceholders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown placeholder %q", name)
+	}
+	return placeholder(arg)
+}
+
+var templateTimeLayouts = map[string]string{
+	"":            time.TimeOnly,
+	"TimeOnly":    time.TimeOnly,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+}
+
+var templateTimeFormats = map[string]Formatter{
+	"elapsed":   ElapsedTimeFormat,
+	"delta":     DeltaTimeFormat,
+	"humanized": HumanizedTimeFormat,
+}
+
+func timePlaceholder(arg string) (EntryWriter, error) {
+	layout, ok := templateTimeLayouts[arg]
+	if !ok {
+		layout = arg
+	}
+	tw := &TimeWriter{CommonWriter: NewCommonWriter(nil).WithValueStyleWriter(TimeStyleWriter)}
+	if format, ok := templateTimeFormats[arg]; ok {
+		tw.Valuer = format
+		return tw, nil
+	}
+	return tw.WithTimeFormat(layout), nil
+}
+
+func levelPlaceholder(arg string) (EntryWriter, error) {
+	styler, err := templateStyler(arg, map[string]StyleWriter{
+		"":      SimpleColoredStyleWriter,
+		"color": SimpleColoredStyleWriter,
+		"bold":  BoldColoredStyleWriter,
+		"bg":    BackgroundBoldColoredStyleWriter,
+		"plain": PlainStyleWriter,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewCommonWriter(DefaultLevelValuer).WithValueStyleWriter(styler), nil
+}
+
+func messagePlaceholder(arg string) (EntryWriter, error) {
+	styler, err := templateStyler(arg, map[string]StyleWriter{
+		"":      SimpleColoredStyleWriter,
+		"color": SimpleColoredStyleWriter,
+		"plain": PlainStyleWriter,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewCommonWriter(DefaultMessageValuer).WithValueStyleWriter(styler), nil
+}
+
+func funcPlaceholder(arg string) (EntryWriter, error) {
+	switch arg {
+	case "", "short":
+		return &FunctionWriter{NewCommonWriter(ShortFunctionFormat).WithValueStyleWriter(SourceStyleWriter)}, nil
+	case "long":
+		return &FunctionWriter{NewCommonWriter(FullFunctionFormat).WithValueStyleWriter(SourceStyleWriter)}, nil
+	default:
+		return nil, fmt.Errorf("unknown argument %q", arg)
+	}
+}
+
+func sourcePlaceholder(arg string) (EntryWriter, error) {
+	switch arg {
+	case "", "short":
+		return &FileLineWriter{NewCommonWriter(ShortFileLineFormat).WithValueStyleWriter(SourceStyleWriter)}, nil
+	case "long":
+		return &FileLineWriter{NewCommonWriter(LongFileLineFormat).WithValueStyleWriter(SourceStyleWriter)}, nil
+	case "module":
+		return &FileLineWriter{NewCommonWriter(ModuleFileLineFormat).WithValueStyleWriter(SourceStyleWriter)}, nil
+	default:
+		return nil, fmt.Errorf("unknown argument %q", arg)
+	}
+}
+
+func attrsPlaceholder(arg string) (EntryWriter, error) {
+	switch arg {
+	case "", "json":
+		return NewPrettyJSONWriter(), nil
+	case "logfmt":
+		return NewLogfmtWriter(), nil
+	default:
+		return nil, fmt.Errorf("unknown argument %q", arg)
+	}
+}
+
+func templateStyler(arg string, stylers map[string]StyleWriter) (StyleWriter, error) {
+	styler, ok := stylers[arg]
+	if !ok {
+		return nil, fmt.Errorf("unknown argument %q", arg)
+	}
+	return styler, nil
+}
@@ -0,0 +1,134 @@
+package prettylog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"unknown placeholder", "{nope}"},
+		{"unknown argument", "{source:medium}"},
+		{"unterminated placeholder", "{time"},
+		{"unterminated section", "{? {time}"},
+		{"unexpected closing brace", "{time} }"},
+		{"nested placeholder", "{time{level}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTemplate(tt.template); err == nil {
+				t.Errorf("expected error for template %q", tt.template)
+			}
+		})
+	}
+}
+
+func TestMustParseTemplatePanicsOnInvalidTemplate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustParseTemplate to panic on invalid template")
+		}
+	}()
+	MustParseTemplate("{nope}")
+}
+
+func TestWithTemplatePanicsOnNil(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected WithTemplate to panic on nil template")
+		}
+	}()
+	tw, _ := ParseTemplate("{nope}")
+	WithTemplate(tw)
+}
+
+func TestTemplateWriter(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(0)
+	tests := []struct {
+		name     string
+		template string
+		record   slog.Record
+		attrs    []any
+		expected string
+	}{
+		{
+			name:     "literals and placeholders",
+			template: "[{level}] {time} {msg}",
+			record:   slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelWarn, "hello", 0),
+			expected: "[WARN] 03:04:05 hello\n",
+		},
+		{
+			name:     "time argument with colons",
+			template: "{time:2006-01-02 15:04} {time:RFC3339}",
+			record:   slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelWarn, "hello", 0),
+			expected: "2024-01-02 03:04 2024-01-02T03:04:05Z\n",
+		},
+		{
+			name:     "relative time",
+			template: "{time:delta} {msg}",
+			record:   slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelWarn, "hello", 0),
+			expected: "Δ0s hello\n",
+		},
+		{
+			name:     "escaped braces",
+			template: "{{{message}}}",
+			record:   slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0),
+			expected: "{hello}\n",
+		},
+		{
+			name:     "empty section collapses",
+			template: "{message}{? at {source}}{? ({attrs:logfmt})}",
+			record:   slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0),
+			expected: "hello\n",
+		},
+		{
+			name:     "non empty section is kept",
+			template: "{message}{? ({attrs:logfmt})}",
+			record:   slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0),
+			attrs:    []any{"key", "value"},
+			expected: "hello (key=value)\n",
+		},
+		{
+			name:     "source section",
+			template: "{message}{? at {source}}",
+			record:   slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", pc),
+			expected: "hello at template_test.go:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			handler := New(
+				WithOutput(buf),
+				WithColor(false),
+				WithTemplate(MustParseTemplate(tt.template)),
+			)
+			tt.record.Add(tt.attrs...)
+			if err := handler.Handle(context.Background(), tt.record); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := buf.String()
+			if strings.HasSuffix(tt.expected, "\n") {
+				if got != tt.expected {
+					t.Errorf("expected %q, got %q", tt.expected, got)
+				}
+				return
+			}
+			if !regexp.MustCompile("^" + regexp.QuoteMeta(tt.expected) + `\d+\n$`).MatchString(got) {
+				t.Errorf("expected %q followed by line number, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package prettylog
+
+import (
+	"log/slog"
+
+	"github.com/fatih/color"
+	"github.com/tidwall/pretty"
+)
+
+// Style is a list of SGR attributes (colors and text decorations) applied together,
+// e.g. Style{color.FgRed, color.Bold}. An empty Style applies no styling.
+type Style []color.Attribute
+
+// LevelStyle is the styling of a log level.
+type LevelStyle struct {
+	// Text is used for text colored by level, like messages and keys.
+	Text Style
+	// Background is used for text highlighted by level, like the level badge.
+	// It should include a contrasting foreground color.
+	Background Style
+}
+
+// Theme is the set of colors used by the built-in [Styler]s and attribute writers.
+//
+// Set it with [WithTheme]. It is available to custom stylers and writers through [RecordData.Theme].
+type Theme struct {
+	// Name is the name of the theme.
+	Name string
+
+	// Error is the style for levels >= slog.LevelError.
+	Error LevelStyle
+	// Warn is the style for levels >= slog.LevelWarn.
+	Warn LevelStyle
+	// Info is the style for levels >= slog.LevelInfo.
+	Info LevelStyle
+	// Debug is the style for levels >= slog.LevelDebug.
+	Debug LevelStyle
+	// Other is the style for levels below slog.LevelDebug.
+	Other LevelStyle
+
+	// Key is the style of keys written by [CommonWriter]s with [KeyColoredStyleWriter].
+	// If empty, keys are bold and colored by level.
+	Key Style
+	// Time is the style of timestamps written with [TimeStyleWriter].
+	Time Style
+	// Source is the style of function names and file paths written with [SourceStyleWriter].
+	Source Style
+
+	// Attrs is the style of attribute keys and values written by [PrettyJSONWriter] and [LogfmtWriter].
+	Attrs *pretty.Style
+}
+
+// Level returns the style of the given level.
+func (t *Theme) Level(lvl slog.Level) LevelStyle {
+	switch {
+	case lvl >= slog.LevelError:
+		return t.Error
+	case lvl >= slog.LevelWarn:
+		return t.Warn
+	case lvl >= slog.LevelInfo:
+		return t.Info
+	case lvl >= slog.LevelDebug:
+		return t.Debug
+	default:
+		return t.Other
+	}
+}
+
+// DefaultTheme is the theme used when no theme is set.
+//
+// Colors: Error=Red, Warn=Yellow, Info=Green, Debug=Cyan, others=White,
+// with [pretty.TerminalStyle] for attributes.
+var DefaultTheme = &Theme{
+	Name:  "default",
+	Error: LevelStyle{Text: Style{color.FgRed}, Background: Style{color.BgRed, color.FgWhite}},
+	Warn:  LevelStyle{Text: Style{color.FgYellow}, Background: Style{color.BgYellow, color.FgWhite}},
+	Info:  LevelStyle{Text: Style{color.FgGreen}, Background: Style{color.BgGreen, color.FgWhite}},
+	Debug: LevelStyle{Text: Style{color.FgCyan}, Background: Style{color.BgCyan, color.FgWhite}},
+	Other: LevelStyle{Text: Style{color.FgWhite}, Background: Style{color.BgWhite, color.FgBlack}},
+	Attrs: pretty.TerminalStyle,
+}
+
+// DarkTheme is a high intensity theme for terminals with dark backgrounds.
+var DarkTheme = &Theme{
+	Name:   "dark",
+	Error:  LevelStyle{Text: Style{color.FgHiRed}, Background: Style{color.BgHiRed, color.FgBlack}},
+	Warn:   LevelStyle{Text: Style{color.FgHiYellow}, Background: Style{color.BgHiYellow, color.FgBlack}},
+	Info:   LevelStyle{Text: Style{color.FgHiGreen}, Background: Style{color.BgHiGreen, color.FgBlack}},
+	Debug:  LevelStyle{Text: Style{color.FgHiCyan}, Background: Style{color.BgHiCyan, color.FgBlack}},
+	Other:  LevelStyle{Text: Style{color.FgHiWhite}, Background: Style{color.BgHiWhite, color.FgBlack}},
+	Key:    Style{color.FgHiBlue, color.Bold},
+	Time:   Style{color.FgHiBlack},
+	Source: Style{color.FgHiBlack},
+	Attrs: &pretty.Style{
+		Key:      [2]string{"\x1b[1m\x1b[94m", "\x1b[0m"},
+		String:   [2]string{"\x1b[92m", "\x1b[0m"},
+		Number:   [2]string{"\x1b[93m", "\x1b[0m"},
+		True:     [2]string{"\x1b[96m", "\x1b[0m"},
+		False:    [2]string{"\x1b[96m", "\x1b[0m"},
+		Null:     [2]string{"\x1b[90m", "\x1b[0m"},
+		Escape:   [2]string{"\x1b[95m", "\x1b[0m"},
+		Brackets: [2]string{"\x1b[1m", "\x1b[0m"},
+		Append:   pretty.TerminalStyle.Append,
+	},
+}
+
+// LightTheme is a theme for terminals with light backgrounds. It avoids yellow and white text.
+var LightTheme = &Theme{
+	Name:   "light",
+	Error:  LevelStyle{Text: Style{color.FgRed}, Background: Style{color.BgRed, color.FgWhite}},
+	Warn:   LevelStyle{Text: Style{color.FgMagenta}, Background: Style{color.BgMagenta, color.FgWhite}},
+	Info:   LevelStyle{Text: Style{color.FgBlue}, Background: Style{color.BgBlue, color.FgWhite}},
+	Debug:  LevelStyle{Text: Style{color.FgCyan}, Background: Style{color.BgCyan, color.FgBlack}},
+	Other:  LevelStyle{Text: Style{color.FgBlack}, Background: Style{color.BgBlack, color.FgWhite}},
+	Key:    Style{color.FgBlue, color.Bold},
+	Time:   Style{color.FgHiBlack},
+	Source: Style{color.FgHiBlack},
+	Attrs: &pretty.Style{
+		Key:      [2]string{"\x1b[1m\x1b[34m", "\x1b[0m"},
+		String:   [2]string{"\x1b[32m", "\x1b[0m"},
+		Number:   [2]string{"\x1b[35m", "\x1b[0m"},
+		True:     [2]string{"\x1b[36m", "\x1b[0m"},
+		False:    [2]string{"\x1b[36m", "\x1b[0m"},
+		Null:     [2]string{"\x1b[90m", "\x1b[0m"},
+		Escape:   [2]string{"\x1b[31m", "\x1b[0m"},
+		Brackets: [2]string{"\x1b[1m", "\x1b[0m"},
+		Append:   pretty.TerminalStyle.Append,
+	},
+}
+
+// SolarizedTheme is a theme for terminals using the Solarized palette.
+//
+// It relies on the terminal mapping the 16 ANSI colors to the Solarized palette,
+// where the bright variants are the base tones.
+var SolarizedTheme = &Theme{
+	Name:   "solarized",
+	Error:  LevelStyle{Text: Style{color.FgRed}, Background: Style{color.BgRed, color.FgHiWhite}},
+	Warn:   LevelStyle{Text: Style{color.FgYellow}, Background: Style{color.BgYellow, color.FgHiWhite}},
+	Info:   LevelStyle{Text: Style{color.FgGreen}, Background: Style{color.BgGreen, color.FgHiWhite}},
+	Debug:  LevelStyle{Text: Style{color.FgBlue}, Background: Style{color.BgBlue, color.FgHiWhite}},
+	Other:  LevelStyle{Text: Style{color.FgHiCyan}, Background: Style{color.BgHiCyan, color.FgHiWhite}},
+	Key:    Style{color.FgBlue, color.Bold},
+	Time:   Style{color.FgHiGreen},
+	Source: Style{color.FgHiGreen},
+	Attrs: &pretty.Style{
+		Key:      [2]string{"\x1b[1m\x1b[34m", "\x1b[0m"},
+		String:   [2]string{"\x1b[36m", "\x1b[0m"},
+		Number:   [2]string{"\x1b[35m", "\x1b[0m"},
+		True:     [2]string{"\x1b[33m", "\x1b[0m"},
+		False:    [2]string{"\x1b[33m", "\x1b[0m"},
+		Null:     [2]string{"\x1b[92m", "\x1b[0m"},
+		Escape:   [2]string{"\x1b[91m", "\x1b[0m"},
+		Brackets: [2]string{"\x1b[1m", "\x1b[0m"},
+		Append:   pretty.TerminalStyle.Append,
+	},
+}
+
+// MonochromeBoldTheme is a theme without colors, for terminals or people that cannot
+// distinguish them. Levels are distinguished by text decorations only.
+var MonochromeBoldTheme = &Theme{
+	Name:   "monochrome-bold",
+	Error:  LevelStyle{Text: Style{color.Bold, color.Underline}, Background: Style{color.ReverseVideo, color.Bold, color.Underline}},
+	Warn:   LevelStyle{Text: Style{color.Bold}, Background: Style{color.ReverseVideo, color.Bold}},
+	Info:   LevelStyle{Text: Style{}, Background: Style{color.ReverseVideo}},
+	Debug:  LevelStyle{Text: Style{color.Faint}, Background: Style{color.ReverseVideo, color.Faint}},
+	Other:  LevelStyle{Text: Style{color.Faint}, Background: Style{color.Faint}},
+	Key:    Style{color.Bold},
+	Time:   Style{color.Faint},
+	Source: Style{color.Faint},
+	Attrs: &pretty.Style{
+		Key:      [2]string{"\x1b[1m", "\x1b[0m"},
+		Null:     [2]string{"\x1b[2m", "\x1b[0m"},
+		Brackets: [2]string{"\x1b[1m", "\x1b[0m"},
+		Append:   pretty.TerminalStyle.Append,
+	},
+}
+
+// Themes are the built-in themes by name.
+var Themes = map[string]*Theme{
+	DefaultTheme.Name:        DefaultTheme,
+	DarkTheme.Name:           DarkTheme,
+	LightTheme.Name:          LightTheme,
+	SolarizedTheme.Name:      SolarizedTheme,
+	MonochromeBoldTheme.Name: MonochromeBoldTheme,
+}
+
+// themeOf returns the theme of the record, or [DefaultTheme] if not set.
+func themeOf(info RecordData) *Theme {
+	if info.Theme == nil {
+		return DefaultTheme
+	}
+	return info.Theme
+}
+
+// attrsStyleOf returns the attribute style of the record theme, or [pretty.TerminalStyle] if not set.
+func attrsStyleOf(info RecordData) *pretty.Style {
+	if style := themeOf(info).Attrs; style != nil {
+		return style
+	}
+	return pretty.TerminalStyle
+}
@@ -0,0 +1,126 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/tidwall/pretty"
+)
+
+func TestThemeLevel(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected LevelStyle
+	}{
+		{slog.LevelError + 4, DefaultTheme.Error},
+		{slog.LevelError, DefaultTheme.Error},
+		{slog.LevelWarn, DefaultTheme.Warn},
+		{slog.LevelInfo, DefaultTheme.Info},
+		{slog.LevelDebug, DefaultTheme.Debug},
+		{slog.LevelDebug - 4, DefaultTheme.Other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			got := DefaultTheme.Level(tt.level)
+			if got.Text[0] != tt.expected.Text[0] {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStylersUseTheme(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	theme := &Theme{
+		Info:   LevelStyle{Text: Style{color.FgMagenta}, Background: Style{color.BgBlue}},
+		Key:    Style{color.FgHiBlue},
+		Time:   Style{color.Faint},
+		Source: Style{color.Underline},
+	}
+	info := RecordData{
+		Record: slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0),
+		Theme:  theme,
+	}
+
+	tests := []struct {
+		name     string
+		styler   Styler
+		expected string
+	}{
+		{"simple", SimpleColoredStyler, color.New(color.FgMagenta).Sprint("text")},
+		{"bold", BoldColoredStyler, color.New(color.FgMagenta, color.Bold).Sprint("text")},
+		{"background", BackgroundBoldColoredStyler, color.New(color.BgBlue, color.Bold).Sprint(" text ")},
+		{"key", KeyColoredStyler, color.New(color.FgHiBlue).Sprint("text")},
+		{"time", TimeStyler, color.New(color.Faint).Sprint("text")},
+		{"source", SourceStyler, color.New(color.Underline).Sprint("text")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.styler(info, "text"); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStylersWithoutTheme(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	info := RecordData{Record: slog.NewRecord(time.Now(), slog.LevelError, "message", 0)}
+	if got := SimpleColoredStyler(info, "text"); got != color.New(color.FgRed).Sprint("text") {
+		t.Errorf("expected default theme colors, got %q", got)
+	}
+	if got := KeyColoredStyler(info, "text"); got != color.New(color.FgRed, color.Bold).Sprint("text") {
+		t.Errorf("expected bold level color for keys, got %q", got)
+	}
+	if got := TimeStyler(info, "text"); got != "text" {
+		t.Errorf("expected plain time, got %q", got)
+	}
+}
+
+func TestWithTheme(t *testing.T) {
+	buf := &bytes.Buffer{}
+	theme := &Theme{
+		Attrs: &pretty.Style{
+			Key:    [2]string{"<k>", "</k>"},
+			String: [2]string{"<s>", "</s>"},
+		},
+	}
+	handler := New(
+		WithOutput(buf),
+		WithColor(true),
+		WithTheme(theme),
+		WithWriters(NewLogfmtWriter(), NewPrettyJSONWriter().WithPrettyOptions(&pretty.Options{Width: 80})),
+	)
+	slog.New(handler).Info("message", "key", "value")
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "<k>key</k>=<s>value</s>\n") {
+		t.Errorf("expected logfmt to use theme attrs style, got %q", got)
+	}
+	if !strings.Contains(got, `<k>"key"</k>`) || !strings.Contains(got, `<s>"value"</s>`) {
+		t.Errorf("expected JSON to use theme attrs style, got %q", got)
+	}
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for name, theme := range Themes {
+		if theme.Name != name {
+			t.Errorf("theme %q registered as %q", theme.Name, name)
+		}
+		if theme.Attrs == nil {
+			t.Errorf("theme %q has no attribute style", name)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package prettylog
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultEllipsis is the marker appended to values truncated by [WrapOptions.MaxLines].
+const DefaultEllipsis = "…"
+
+// minWrapWidth is the minimum width of wrapped lines. If the hanging indent leaves
+// less room than this, continuation lines are not indented.
+const minWrapWidth = 20
+
+// WrapOptions configures wrapping of long values to the terminal width. See [WithWrap].
+type WrapOptions struct {
+	// Width is the width used when it cannot be detected from the output or from the COLUMNS
+	// environment variable. If zero and the width cannot be detected, values are not wrapped.
+	//
+	// When passed to writers through [RecordData.Wrap], Width is the resolved width.
+	Width int
+	// MaxLines truncates wrapped values to this number of lines, marking the cut with Ellipsis.
+	// Zero means no limit.
+	MaxLines int
+	// Ellipsis is the marker of truncated values. If empty, [DefaultEllipsis] is used.
+	Ellipsis string
+}
+
+// wrapWidthTTL is how long the terminal width of an output is cached before it is read again,
+// so resizing the terminal is picked up without reading the width on every record.
+const wrapWidthTTL = time.Second
+
+// wrapResolver resolves the [WrapOptions] set by [WithWrap] for the outputs of a handler.
+//
+// The COLUMNS environment variable is read when the option is applied. The terminal width of
+// outputs with a file descriptor is cached for [wrapWidthTTL].
+type wrapResolver struct {
+	opts WrapOptions
+	// fallback is the resolved options of outputs without a terminal, or nil to not wrap them.
+	fallback *WrapOptions
+
+	mu     sync.RWMutex
+	widths map[uintptr]*terminalWrap
+
+	// now and fdWidth are replaced in tests.
+	now     func() time.Time
+	fdWidth func(fd uintptr) (int, bool)
+}
+
+// terminalWrap is the cached resolution of an output with a file descriptor.
+type terminalWrap struct {
+	resolved  atomic.Pointer[WrapOptions]
+	checkedAt atomic.Int64
+}
+
+func newWrapResolver(opts WrapOptions) *wrapResolver {
+	if opts.Ellipsis == "" {
+		opts.Ellipsis = DefaultEllipsis
+	}
+	r := &wrapResolver{
+		opts:    opts,
+		widths:  make(map[uintptr]*terminalWrap),
+		now:     time.Now,
+		fdWidth: fdWidth,
+	}
+	width := opts.Width
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		width = columns
+	}
+	r.fallback = r.withWidth(width)
+	return r
+}
+
+// withWidth returns the options with the given width, or nil if the width is unknown.
+func (r *wrapResolver) withWidth(width int) *WrapOptions {
+	if width <= 0 {
+		return nil
+	}
+	if r.fallback != nil && r.fallback.Width == width {
+		return r.fallback
+	}
+	resolved := r.opts
+	resolved.Width = width
+	return &resolved
+}
+
+// resolve returns the options with the width of w, or nil if wrapping is disabled.
+//
+// The width is taken from the terminal behind w, then the COLUMNS environment variable,
+// then the configured width.
+func (r *wrapResolver) resolve(w io.Writer) *WrapOptions {
+	if r == nil {
+		return nil
+	}
+	fd, ok := fdOf(w)
+	if !ok {
+		return r.fallback
+	}
+
+	r.mu.RLock()
+	tw := r.widths[fd]
+	r.mu.RUnlock()
+	if tw == nil {
+		r.mu.Lock()
+		if tw = r.widths[fd]; tw == nil {
+			tw = &terminalWrap{}
+			r.widths[fd] = tw
+		}
+		r.mu.Unlock()
+	}
+
+	now := r.now().UnixNano()
+	if checkedAt := tw.checkedAt.Load(); checkedAt != 0 && now-checkedAt < int64(wrapWidthTTL) {
+		return tw.resolved.Load()
+	}
+	resolved := r.fallback
+	if width, ok := r.fdWidth(fd); ok && width > 0 {
+		if current := tw.resolved.Load(); current != nil && current.Width == width {
+			resolved = current
+		} else {
+			resolved = r.withWidth(width)
+		}
+	}
+	tw.resolved.Store(resolved)
+	tw.checkedAt.Store(now)
+	return resolved
+}
+
+// fdOf returns the file descriptor of w, or of the writer it wraps.
+func fdOf(w io.Writer) (uintptr, bool) {
+	for {
+		if w == nil {
+			return 0, false
+		}
+		if fd, ok := (w).(interface{ Fd() uintptr }); ok {
+			return fd.Fd(), true
+		}
+		if uw, ok := w.(interface{ Unwrap() io.Writer }); ok {
+			w = uw.Unwrap()
+			continue
+		}
+		return 0, false
+	}
+}
+
+// wrapText soft-wraps s so that no line is wider than width columns.
+//
+// The first line starts at column start. Continuation lines are indented by start
+// spaces (a hanging indent), unless that leaves less than [minWrapWidth] columns.
+// Lines are broken at spaces when possible, and words wider than a line are split.
+// Existing new lines are kept, and continuation lines after them are indented as well.
+// Trailing new lines are kept as is.
+//
+// ANSI SGR sequences active at a line break are reset before the break and restored after
+// the indent, so the indent is never styled.
+//
+// If maxLines is positive, the text is truncated to maxLines lines, and ellipsis is
+// appended to the last line, after the last whole word that fits.
+func wrapText(s string, start, width, maxLines int, ellipsis string) string {
+	indent := start
+	if width-indent < minWrapWidth {
+		indent = 0
+	}
+	ww := &textWrapper{
+		width:     width,
+		indent:    indent,
+		maxLines:  maxLines,
+		ellipsis:  ellipsis,
+		column:    start,
+		lineCount: 1,
+	}
+	text := strings.TrimRight(s, "\n")
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 && !ww.newLine() {
+			break
+		}
+		if !ww.writeLine(line) {
+			break
+		}
+	}
+	ww.sb.WriteString(s[len(text):])
+	return ww.sb.String()
+}
+
+type textWrapper struct {
+	sb        strings.Builder
+	width     int
+	indent    int
+	maxLines  int
+	ellipsis  string
+	column    int
+	lineCount int
+	// active are the SGR sequences active since the last reset.
+	active []string
+}
+
+// writeLine writes a line without new lines, wrapping it. It returns false if the text is truncated.
+func (ww *textWrapper) writeLine(line string) bool {
+	pendingSpaces := ""
+	for len(line) > 0 {
+		token, isSpace := nextWrapToken(line)
+		line = line[len(token):]
+		if isSpace {
+			pendingSpaces += token
+			continue
+		}
+		tokenWidth := VisibleWidth(token)
+		spacesWidth := VisibleWidth(pendingSpaces)
+		if ww.column+spacesWidth+tokenWidth <= ww.width || tokenWidth == 0 {
+			ww.write(pendingSpaces)
+			ww.write(token)
+			ww.column += spacesWidth + tokenWidth
+			pendingSpaces = ""
+			continue
+		}
+		pendingSpaces = ""
+		if tokenWidth <= ww.width-ww.indent {
+			// The word fits in a line of its own.
+			if ww.column > ww.indent && !ww.newLine() {
+				return false
+			}
+			ww.write(token)
+			ww.column += tokenWidth
+			continue
+		}
+		// The word is wider than a line: split it.
+		if !ww.writeSplit(token) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeSplit writes a word that is wider than a line, breaking it at rune boundaries.
+func (ww *textWrapper) writeSplit(word string) bool {
+	for i := 0; i < len(word); {
+		if n := ansiSequenceLen(word[i:]); n > 0 {
+			ww.write(word[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(word[i:])
+		rw := RuneWidth(r)
+		if ww.column+rw > ww.width && ww.column > ww.indent {
+			if !ww.newLine() {
+				return false
+			}
+		}
+		ww.sb.WriteString(word[i : i+size])
+		ww.column += rw
+		i += size
+	}
+	return true
+}
+
+// newLine breaks the current line. It returns false, after truncating the current line,
+// if the line limit is reached.
+func (ww *textWrapper) newLine() bool {
+	if ww.maxLines > 0 && ww.lineCount >= ww.maxLines {
+		ww.truncate()
+		return false
+	}
+	if len(ww.active) > 0 {
+		ww.sb.WriteString("\x1b[0m")
+	}
+	ww.sb.WriteByte('\n')
+	ww.sb.WriteString(strings.Repeat(" ", ww.indent))
+	for _, seq := range ww.active {
+		ww.sb.WriteString(seq)
+	}
+	ww.column = ww.indent
+	ww.lineCount++
+	return true
+}
+
+// truncate cuts the current line so the ellipsis fits in the width, and appends the ellipsis.
+func (ww *textWrapper) truncate() {
+	ellipsisWidth := VisibleWidth(ww.ellipsis)
+	written := ww.sb.String()
+	lineStart := strings.LastIndexByte(written, '\n') + 1
+	line := written[lineStart:]
+	column := ww.column - VisibleWidth(line)
+	limit := ww.width - ellipsisWidth
+
+	var kept strings.Builder
+	i := 0
+	for i < len(line) {
+		if n := ansiSequenceLen(line[i:]); n > 0 {
+			kept.WriteString(line[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if column+RuneWidth(r) > limit {
+			break
+		}
+		kept.WriteString(line[i : i+size])
+		column += RuneWidth(r)
+		i += size
+	}
+	text := kept.String()
+	if i < len(line) && line[i] != ' ' {
+		// Do not leave a partial word before the ellipsis.
+		if space := strings.LastIndexByte(text, ' '); space > 0 {
+			text = text[:space]
+		}
+	}
+	ww.sb.Reset()
+	ww.sb.WriteString(written[:lineStart])
+	ww.sb.WriteString(strings.TrimRight(text, " "))
+	ww.sb.WriteString(ww.ellipsis)
+	if len(ww.active) > 0 {
+		ww.sb.WriteString("\x1b[0m")
+	}
+}
+
+// write writes s, tracking the SGR sequences it contains.
+func (ww *textWrapper) write(s string) {
+	ww.sb.WriteString(s)
+	for i := 0; i < len(s); i++ {
+		n := ansiSequenceLen(s[i:])
+		if n == 0 {
+			continue
+		}
+		seq := s[i : i+n]
+		if strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m") {
+			if seq == "\x1b[0m" || seq == "\x1b[m" {
+				ww.active = ww.active[:0]
+			} else {
+				ww.active = append(ww.active, seq)
+			}
+		}
+		i += n - 1
+	}
+}
+
+// nextWrapToken returns the next run of spaces, or the next word. ANSI sequences are part of words.
+func nextWrapToken(s string) (token string, isSpace bool) {
+	if s[0] == ' ' {
+		i := 0
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		return s[:i], true
+	}
+	i := 0
+	for i < len(s) {
+		if n := ansiSequenceLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		if s[i] == ' ' {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return s[:i], false
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package prettylog
+
+// fdWidth reports that the terminal width cannot be detected on this platform.
+func fdWidth(fd uintptr) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,183 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		start    int
+		width    int
+		maxLines int
+		expected string
+	}{
+		{
+			name:     "fits",
+			input:    "short message",
+			start:    5,
+			width:    40,
+			expected: "short message",
+		},
+		{
+			name:     "hanging indent",
+			input:    "the quick brown fox jumps over the lazy dog",
+			start:    6,
+			width:    30,
+			expected: "the quick brown fox\n      jumps over the lazy dog",
+		},
+		{
+			name:     "long word is split",
+			input:    strings.Repeat("x", 50),
+			start:    0,
+			width:    20,
+			expected: strings.Repeat("x", 20) + "\n" + strings.Repeat("x", 20) + "\n" + strings.Repeat("x", 10),
+		},
+		{
+			name:     "existing new lines are indented",
+			input:    "first\nsecond\n",
+			start:    4,
+			width:    40,
+			expected: "first\n    second\n",
+		},
+		{
+			name:     "narrow terminal drops indent",
+			input:    "alpha beta gamma delta",
+			start:    12,
+			width:    24,
+			expected: "alpha beta\ngamma delta",
+		},
+		{
+			name:     "wide runes",
+			input:    "日本語日本語日本語日本語",
+			start:    0,
+			width:    20,
+			expected: "日本語日本語日本語日\n本語",
+		},
+		{
+			name:     "truncated",
+			input:    "one two three four five six seven eight nine ten eleven twelve",
+			start:    0,
+			width:    20,
+			maxLines: 2,
+			expected: "one two three four\nfive six seven…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapText(tt.input, tt.start, tt.width, tt.maxLines, DefaultEllipsis)
+			if got != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, got)
+			}
+			for i, line := range strings.Split(got, "\n") {
+				start := 0
+				if i == 0 {
+					start = tt.start
+				}
+				if w := start + VisibleWidth(line); w > tt.width {
+					t.Errorf("line %d is %d columns wide, more than %d: %q", i, w, tt.width, line)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapTextKeepsColorsOffIndent(t *testing.T) {
+	input := "\x1b[31mred words that wrap around\x1b[0m"
+	got := wrapText(input, 4, 24, 0, DefaultEllipsis)
+	expected := "\x1b[31mred words that wrap\x1b[0m\n    \x1b[31maround\x1b[0m"
+	if got != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, got)
+	}
+}
+
+func TestWrapResolver(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	var disabled *wrapResolver
+	if disabled.resolve(&bytes.Buffer{}) != nil {
+		t.Error("expected nil resolver to disable wrapping")
+	}
+	if newWrapResolver(WrapOptions{}).resolve(&bytes.Buffer{}) != nil {
+		t.Error("expected unknown width to disable wrapping")
+	}
+
+	resolved := newWrapResolver(WrapOptions{Width: 100}).resolve(&bytes.Buffer{})
+	if resolved == nil || resolved.Width != 100 || resolved.Ellipsis != DefaultEllipsis {
+		t.Errorf("expected configured width, got %+v", resolved)
+	}
+
+	t.Setenv("COLUMNS", "72")
+	r := newWrapResolver(WrapOptions{Width: 100})
+	t.Setenv("COLUMNS", "50")
+	if resolved := r.resolve(&bytes.Buffer{}); resolved == nil || resolved.Width != 72 {
+		t.Errorf("expected width from COLUMNS when the option was applied, got %+v", resolved)
+	}
+}
+
+func TestWrapResolverCachesTerminalWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	_, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	r := newWrapResolver(WrapOptions{Width: 100})
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	calls, width := 0, 80
+	r.fdWidth = func(fd uintptr) (int, bool) {
+		calls++
+		return width, true
+	}
+
+	for range 3 {
+		if resolved := r.resolve(w); resolved == nil || resolved.Width != 80 {
+			t.Fatalf("expected terminal width, got %+v", resolved)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the terminal width to be read once, got %d reads", calls)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { r.resolve(w) }); allocs != 0 {
+		t.Errorf("expected no allocations for a cached width, got %v", allocs)
+	}
+
+	width = 120
+	now = now.Add(wrapWidthTTL)
+	if resolved := r.resolve(w); resolved == nil || resolved.Width != 120 {
+		t.Errorf("expected the resized width after the TTL, got %+v", resolved)
+	}
+	if calls != 2 {
+		t.Errorf("expected the terminal width to be read again after the TTL, got %d reads", calls)
+	}
+}
+
+func TestWithWrap(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWrap(WrapOptions{Width: 40, MaxLines: 2}),
+		WithWriters(
+			NewCommonWriter(DefaultMessageValuer).WithStaticKey("message"),
+			NewCommonWriter(func(RecordData) string { return "v" }).WithStaticKey("k"),
+			CompactNewLineWriter,
+		),
+	)
+	slog.New(handler).Info("a long message that does not fit in forty columns and goes on and on")
+
+	expected := "message a long message that does not fit\n" +
+		"        in forty columns and goes on…\n" +
+		"k       v\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
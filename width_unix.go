@@ -0,0 +1,14 @@
+//go:build unix
+
+package prettylog
+
+import "golang.org/x/sys/unix"
+
+// fdWidth returns the number of columns of the terminal behind fd.
+func fdWidth(fd uintptr) (int, bool) {
+	ws, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, false
+	}
+	return int(ws.Col), true
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package prettylog
+
+import "golang.org/x/sys/windows"
+
+// fdWidth returns the number of columns of the console behind fd.
+func fdWidth(fd uintptr) (int, bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(fd), &info); err != nil {
+		return 0, false
+	}
+	return int(info.Window.Right - info.Window.Left + 1), true
+}
@@ -1,17 +1,17 @@
 package prettylog
 
 import (
-	"strings"
+	"bytes"
 )
 
 // EntryWriter is the interface for components that write parts of log entries.
 // Each EntryWriter is responsible for formatting and outputting a specific
 // component of the log entry (e.g., level, message, timestamp).
 type EntryWriter interface {
-	// Key returns the key to be used for this entry.
+	// KeyLen returns the visible width of the key of this entry, or 0 if it has no key.
 	//
-	// If colored, this should also include the ANSI color codes
-	// as length of key
+	// The widest key sets [RecordData.KeyFieldLength], which aligns the key column.
+	// Use [VisibleWidth] to measure styled keys, so ANSI codes and wide runes are accounted for.
 	KeyLen(info RecordData) int
 
 	// Write writes the entry to the given buffer to [RecordData.Buffer] inside info.
@@ -37,7 +37,12 @@ func (UnimplementedEntryWriter) Write(info RecordData)      {}
 //   - Empty string if buffer is empty
 //   - Newline if the writer has no key
 //   - Space for subsequent entries
+//
+// If [RecordData.Layout] is [CompactLayout], the result of [CompactPrefix] is returned instead.
 func DefaultPrefix(info RecordData, this *CommonWriter) string {
+	if info.Layout == CompactLayout {
+		return CompactPrefix(info, this)
+	}
 	if info.Buffer.Len() == 0 {
 		return ""
 	}
@@ -53,29 +58,47 @@ type PrefixFunc func(info RecordData, this *CommonWriter) string
 // CommonWriter is a common implementation of EntryWriter that writes a key-value pair.
 // It provides configurable formatting for both keys and values, along with styling options.
 //
+// Keys and values are styled by their [StyleWriter]. KeyStyler and ValueStyler default to adapters
+// of the style writers, so they can still be called or wrapped. A [Styler] set with WithKeyColorizer
+// or WithValueColorizer takes precedence for compatibility.
+//
 // Use NewCommonWriter to create a new instance with proper field initialization,
 // then customize it using the provided methods.
 type CommonWriter struct {
-	Key         Formatter
-	Valuer      Formatter
-	Prefix      PrefixFunc
-	KeyStyler   Styler
-	ValueStyler Styler
+	Key              Formatter
+	Valuer           Formatter
+	Prefix           PrefixFunc
+	KeyStyler        Styler
+	ValueStyler      Styler
+	KeyStyleWriter   StyleWriter
+	ValueStyleWriter StyleWriter
+
+	// keyAdapter and valueAdapter are the default KeyStyler and ValueStyler.
+	keyAdapter   Styler
+	valueAdapter Styler
+	// keyAdapted and valueAdapted report whether KeyStyler and ValueStyler are the adapters,
+	// in which case the style writers are used directly.
+	keyAdapted   bool
+	valueAdapted bool
 }
 
 // NewCommonWriter creates a new CommonWriter with the given value formatter.
 // The value formatter extracts the actual value from the RecordData.
 //
 // The key is empty by default and can be set using WithKey or WithStaticKey methods.
-// Default styling uses bold colored keys and plain values.
+// Default styling uses keys styled by [KeyColoredStyleWriter] and plain values.
+// KeyStyler and ValueStyler are set to adapters of the style writers.
 func NewCommonWriter(valuer Formatter) *CommonWriter {
-	return &CommonWriter{
-		Key:         Static(""),
-		Valuer:      valuer,
-		Prefix:      DefaultPrefix,
-		KeyStyler:   BoldColoredStyler,
-		ValueStyler: PlainStyler,
+	cw := &CommonWriter{
+		Key:              Static(""),
+		Valuer:           valuer,
+		Prefix:           DefaultPrefix,
+		KeyStyleWriter:   KeyColoredStyleWriter,
+		ValueStyleWriter: PlainStyleWriter,
 	}
+	cw.resetKeyStyler()
+	cw.resetValueStyler()
+	return cw
 }
 
 // WithKey sets the key formatter for this CommonWriter.
@@ -108,27 +131,114 @@ func (cw *CommonWriter) WithPrefix(f PrefixFunc) *CommonWriter {
 
 // WithKeyColorizer sets the styler for the key portion of this CommonWriter.
 // The key styler applies colors and formatting to the key text.
+//
+// The styler takes precedence over the key style writer.
 func (cw *CommonWriter) WithKeyColorizer(c Styler) *CommonWriter {
 	cw.KeyStyler = c
+	cw.keyAdapted = false
 	return cw
 }
 
 // WithValueColorizer sets the styler for the value portion of this CommonWriter.
 // The value styler applies colors and formatting to the value text.
+//
+// The styler takes precedence over the value style writer.
 func (cw *CommonWriter) WithValueColorizer(c Styler) *CommonWriter {
 	cw.ValueStyler = c
+	cw.valueAdapted = false
+	return cw
+}
+
+// WithKeyStyleWriter sets the style writer for the key portion of this CommonWriter,
+// and replaces the key styler set by WithKeyColorizer with an adapter of sw.
+func (cw *CommonWriter) WithKeyStyleWriter(sw StyleWriter) *CommonWriter {
+	cw.KeyStyleWriter = sw
+	cw.resetKeyStyler()
+	return cw
+}
+
+// WithValueStyleWriter sets the style writer for the value portion of this CommonWriter,
+// and replaces the value styler set by WithValueColorizer with an adapter of sw.
+func (cw *CommonWriter) WithValueStyleWriter(sw StyleWriter) *CommonWriter {
+	cw.ValueStyleWriter = sw
+	cw.resetValueStyler()
 	return cw
 }
 
+// resetKeyStyler sets KeyStyler to the adapter of KeyStyleWriter.
+func (cw *CommonWriter) resetKeyStyler() {
+	if cw.keyAdapter == nil {
+		cw.keyAdapter = adaptStyleWriter(&cw.KeyStyleWriter)
+	}
+	cw.KeyStyler = cw.keyAdapter
+	cw.keyAdapted = true
+}
+
+// resetValueStyler sets ValueStyler to the adapter of ValueStyleWriter.
+func (cw *CommonWriter) resetValueStyler() {
+	if cw.valueAdapter == nil {
+		cw.valueAdapter = adaptStyleWriter(&cw.ValueStyleWriter)
+	}
+	cw.ValueStyler = cw.valueAdapter
+	cw.valueAdapted = true
+}
+
+// adaptStyleWriter returns a [Styler] returning what the style writer currently in sw writes,
+// or the string unchanged if there is none.
+func adaptStyleWriter(sw *StyleWriter) Styler {
+	return func(info RecordData, s string) string {
+		if *sw == nil {
+			return s
+		}
+		return styleString(*sw, info, s)
+	}
+}
+
+// keyStyler returns the key styler, or nil if it is the default adapter of the key style writer.
+func (cw *CommonWriter) keyStyler() Styler {
+	if cw.keyAdapted {
+		return nil
+	}
+	return cw.KeyStyler
+}
+
+// valueStyler returns the value styler, or nil if it is the default adapter of the value style writer.
+func (cw *CommonWriter) valueStyler() Styler {
+	if cw.valueAdapted {
+		return nil
+	}
+	return cw.ValueStyler
+}
+
+// valueStyleWriter returns the style writer of values, adapting the value styler if it is set.
+func (cw *CommonWriter) valueStyleWriter() StyleWriter {
+	if styler := cw.valueStyler(); styler != nil {
+		return styler.StyleWriter()
+	}
+	if cw.ValueStyleWriter != nil {
+		return cw.ValueStyleWriter
+	}
+	return PlainStyleWriter
+}
+
 func (cw *CommonWriter) KeyLen(info RecordData) int {
+	if info.Layout == CompactLayout {
+		// Keys are rendered inline, so they take no part in alignment.
+		return 0
+	}
 	key := cw.Key(info)
 	if key == "" {
 		return 0
 	}
-	if info.Color {
-		return len(cw.KeyStyler(info, key))
+	if !info.Color {
+		return VisibleWidth(key)
 	}
-	return len(key)
+	buf := styleBufferPool.Get().(*bytes.Buffer)
+	writeStyled(info, buf, cw.keyStyler(), cw.KeyStyleWriter, key)
+	width := visibleWidth(buf.Bytes())
+	buf.Reset()
+	styleBufferPool.Put(buf)
+	return width
 }
 
 func (cw *CommonWriter) Write(info RecordData) {
@@ -137,16 +247,71 @@ func (cw *CommonWriter) Write(info RecordData) {
 		return
 	}
 	info.Buffer.WriteString(cw.Prefix(info, cw))
-	key := cw.Key(info)
-	if info.Color {
-		if key != "" {
-			key = cw.KeyStyler(info, key)
+	if key := cw.Key(info); key != "" {
+		cw.writeKey(info, key)
+	}
+	if info.Wrap != nil && info.Layout != CompactLayout {
+		column := currentColumn(info.Buffer)
+		start := info.Buffer.Len()
+		writeStyled(info, info.Buffer, cw.valueStyler(), cw.ValueStyleWriter, value)
+		styled := string(info.Buffer.Bytes()[start:])
+		info.Buffer.Truncate(start)
+		info.Buffer.WriteString(wrapText(styled, column, info.Wrap.Width, info.Wrap.MaxLines, info.Wrap.Ellipsis))
+		return
+	}
+	writeStyled(info, info.Buffer, cw.valueStyler(), cw.ValueStyleWriter, value)
+}
+
+// writeKey writes the styled key, padded to [RecordData.KeyFieldLength] and followed by a
+// space, or followed by '=' in [CompactLayout].
+func (cw *CommonWriter) writeKey(info RecordData, key string) {
+	buf := info.Buffer
+	if info.Layout == CompactLayout {
+		writeStyled(info, buf, cw.keyStyler(), cw.KeyStyleWriter, key)
+		buf.WriteByte('=')
+		return
+	}
+	start := buf.Len()
+	writeStyled(info, buf, cw.keyStyler(), cw.KeyStyleWriter, key)
+	width := visibleWidth(buf.Bytes()[start:])
+	if info.KeyAlignment == KeyAlignFixed && width > info.KeyFieldLength {
+		truncated := TruncateWidth(string(buf.Bytes()[start:]), info.KeyFieldLength, DefaultEllipsis)
+		buf.Truncate(start)
+		buf.WriteString(truncated)
+		width = VisibleWidth(truncated)
+	}
+	padding := max(info.KeyFieldLength-width, 0)
+	for range padding {
+		buf.WriteByte(' ')
+	}
+	if info.KeyAlignment == KeyAlignRight && padding > 0 {
+		// Move the padding in front of the key, which is already written.
+		b := buf.Bytes()[start:]
+		copy(b[padding:], b[:len(b)-padding])
+		for i := range padding {
+			b[i] = ' '
 		}
-		value = cw.ValueStyler(info, value)
 	}
-	if len(key) > 0 {
-		info.Buffer.WriteString(key)
-		info.Buffer.WriteString(strings.Repeat(" ", info.KeyFieldLength-len(key)+1))
+	buf.WriteByte(' ')
+}
+
+// writeStyled writes s to buf, styled by styler if set, or by sw otherwise.
+// s is written unstyled if [RecordData.Color] is false.
+func writeStyled(info RecordData, buf *bytes.Buffer, styler Styler, sw StyleWriter, s string) {
+	switch {
+	case !info.Color:
+		buf.WriteString(s)
+	case styler != nil:
+		buf.WriteString(styler(info, s))
+	case sw != nil:
+		sw(info, buf, s)
+	default:
+		buf.WriteString(s)
 	}
-	info.Buffer.WriteString(value)
+}
+
+// currentColumn returns the visible width of the last line of buf.
+func currentColumn(buf *bytes.Buffer) int {
+	b := buf.Bytes()
+	return visibleWidth(b[bytes.LastIndexByte(b, '\n')+1:])
 }
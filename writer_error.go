@@ -0,0 +1,222 @@
+package prettylog
+
+import (
+	"log/slog"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+var _ EntryWriter = (*ErrorWriter)(nil)
+
+// DefaultErrorWriter is the default entry writer for error valued attributes.
+//
+// It is not part of [DefaultWriters]. Add it before the new line writer to use it:
+//
+//	handler := prettylog.New(
+//	    prettylog.AddWritersBefore(prettylog.DefaultNewLineWriter, prettylog.DefaultErrorWriter),
+//	)
+var DefaultErrorWriter = NewErrorWriter()
+
+// StackTracer is implemented by errors that carry the program counters of the call stack
+// where they were created.
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// CallersProvider is implemented by errors that carry the program counters of the call stack
+// where they were created.
+type CallersProvider interface {
+	Callers() []uintptr
+}
+
+// NewErrorWriter creates a new ErrorWriter with short function and file/line formats.
+func NewErrorWriter() *ErrorWriter {
+	return &ErrorWriter{
+		FunctionFormat: ShortFunctionFormat,
+		FileLineFormat: ShortFileLineFormat,
+		Indent:         "  ",
+	}
+}
+
+// ErrorWriter is a specialized entry writer for attributes whose values are errors.
+// Errors are styled with the error level style of [RecordData.Theme].
+//
+// Every error valued attribute (including the ones collected by [Handler.WithAttrs]) is rendered
+// as a tree: the error chain built by [errors.Unwrap] and [errors.Join] is walked, and each cause is
+// written on its own indented line. If an error implements [StackTracer] or [CallersProvider], its stack trace
+// is written below it, with every frame formatted by FunctionFormat and FileLineFormat, the same
+// [Formatter]s used by [FunctionWriter] and [FileLineWriter].
+type ErrorWriter struct {
+	// FunctionFormat formats the function of a stack frame given in [RecordData.Frame].
+	FunctionFormat Formatter
+	// FileLineFormat formats the file and line of a stack frame given in [RecordData.Frame].
+	FileLineFormat Formatter
+	// Indent is the indentation added for every level of the error tree.
+	Indent string
+}
+
+// WithFunctionFormat sets the formatter for function names of stack frames.
+func (ew *ErrorWriter) WithFunctionFormat(f Formatter) *ErrorWriter {
+	ew.FunctionFormat = f
+	return ew
+}
+
+// WithFileLineFormat sets the formatter for file and line of stack frames.
+func (ew *ErrorWriter) WithFileLineFormat(f Formatter) *ErrorWriter {
+	ew.FileLineFormat = f
+	return ew
+}
+
+// WithIndent sets the indentation added for every level of the error tree.
+func (ew *ErrorWriter) WithIndent(indent string) *ErrorWriter {
+	ew.Indent = indent
+	return ew
+}
+
+// KeyLen implements [EntryWriter] interface. Always returns 0.
+func (ew *ErrorWriter) KeyLen(info RecordData) int {
+	return 0
+}
+
+// Write implements [EntryWriter] interface.
+func (ew *ErrorWriter) Write(info RecordData) {
+	info.WalkAttrs(func(groups []string, a slog.Attr) bool {
+		if a.Value.Kind() != slog.KindAny {
+			return true
+		}
+		err, ok := a.Value.Any().(error)
+		if !ok || err == nil {
+			return true
+		}
+		if info.Buffer.Len() > 0 {
+			info.Buffer.WriteByte('\n')
+		}
+		key := GroupedKey(groups, a.Key) + ":"
+		if info.Color {
+			ansiSequenceOf(info, themeOf(info).Error.Text, true).write(info.Buffer, key)
+		} else {
+			info.Buffer.WriteString(key)
+		}
+		ew.writeError(info, err, 1, "", make(map[error]struct{}))
+		return true
+	})
+}
+
+// maxErrorDepth bounds the depth of the error tree, for chains of errors that cannot be
+// told apart by identity.
+const maxErrorDepth = 32
+
+// writeError writes err and its children at depth.
+//
+// covered is the message of the nearest ancestor that was written in full: errors whose message
+// is part of it are not written again, but their stack traces and children are.
+// seen holds the comparable errors already written, so cyclic chains end.
+func (ew *ErrorWriter) writeError(info RecordData, err error, depth int, covered string, seen map[error]struct{}) {
+	if depth > maxErrorDepth {
+		return
+	}
+	if reflect.TypeOf(err).Comparable() {
+		seen[err] = struct{}{}
+	}
+	var children []error
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		children = []error{e.Unwrap()}
+	case interface{ Unwrap() []error }:
+		children = e.Unwrap()
+	}
+	children = slices.DeleteFunc(slices.Clone(children), func(child error) bool {
+		if child == nil {
+			return true
+		}
+		if !reflect.TypeOf(child).Comparable() {
+			return false
+		}
+		_, ok := seen[child]
+		return ok
+	})
+
+	indent := strings.Repeat(ew.Indent, depth)
+	msg := ownErrorMessage(err, children)
+	if covered != "" && strings.Contains(covered, err.Error()) {
+		msg = ""
+	}
+	if msg != "" {
+		for line := range strings.SplitSeq(msg, "\n") {
+			info.Buffer.WriteByte('\n')
+			info.Buffer.WriteString(indent)
+			writeStyledLine(info, themeOf(info).Error.Text, line)
+		}
+		depth++
+		covered = ""
+		if len(children) > 0 && msg == err.Error() {
+			// The message embeds the messages of the children, e.g. fmt.Errorf("%w and %w", a, b).
+			covered = msg
+		}
+	}
+	// Wrappers that only attach a stack trace have no message of their own,
+	// but their stack trace is still written below the previous line.
+	ew.writeStackTrace(info, err, indent+ew.Indent)
+
+	for _, child := range children {
+		ew.writeError(info, child, depth, covered, seen)
+	}
+}
+
+func (ew *ErrorWriter) writeStackTrace(info RecordData, err error, indent string) {
+	var pcs []uintptr
+	switch e := err.(type) {
+	case StackTracer:
+		pcs = e.StackTrace()
+	case CallersProvider:
+		pcs = e.Callers()
+	}
+	if len(pcs) == 0 {
+		return
+	}
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		sub := info
+		sub.Frame = frame
+		line := "at " + ew.FunctionFormat(sub) + " " + ew.FileLineFormat(sub)
+		info.Buffer.WriteByte('\n')
+		info.Buffer.WriteString(indent)
+		writeStyledLine(info, faintStyle, line)
+		if !more {
+			return
+		}
+	}
+}
+
+// ownErrorMessage returns the part of err's message that is not already
+// contained in the messages of its children.
+//
+// For a wrapping error created by fmt.Errorf("context: %w", err), this is "context".
+// For errors created by [errors.Join], this is an empty string. For errors wrapping several
+// errors in a single message, like fmt.Errorf("%w and %w", a, b), this is the whole message.
+func ownErrorMessage(err error, children []error) string {
+	msg := err.Error()
+	switch len(children) {
+	case 0:
+		return msg
+	case 1:
+		if own, ok := strings.CutSuffix(msg, children[0].Error()); ok {
+			return strings.TrimRight(own, ": ")
+		}
+		return msg
+	default:
+		messages := make([]string, 0, len(children))
+		for _, child := range children {
+			if child != nil {
+				messages = append(messages, child.Error())
+			}
+		}
+		if msg == strings.Join(messages, "\n") {
+			return ""
+		}
+		return msg
+	}
+}
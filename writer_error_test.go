@@ -0,0 +1,152 @@
+package prettylog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string         { return e.msg }
+func (e *stackError) StackTrace() []uintptr { return e.pcs }
+
+func newStackError(msg string) *stackError {
+	pcs := make([]uintptr, 1)
+	runtime.Callers(1, pcs)
+	return &stackError{msg: msg, pcs: pcs}
+}
+
+func TestErrorWriterChain(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(DefaultMessageWriter, NewErrorWriter()),
+	)
+	root := errors.New("connection refused")
+	err := fmt.Errorf("save user: %w", fmt.Errorf("dial tcp: %w", root))
+	slog.New(handler).WithGroup("db").Error("failed", "error", err, "other", "value")
+
+	expected := strings.Join([]string{
+		"failed",
+		"db.error:",
+		"  save user",
+		"    dial tcp",
+		"      connection refused",
+	}, "\n")
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestErrorWriterJoin(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(NewErrorWriter()),
+	)
+	err := fmt.Errorf("cleanup: %w", errors.Join(errors.New("first"), errors.New("second")))
+	slog.New(handler).Error("failed", "err", err)
+
+	expected := strings.Join([]string{
+		"err:",
+		"  cleanup",
+		"    first",
+		"    second",
+	}, "\n")
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestErrorWriterStackTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(NewErrorWriter().WithFunctionFormat(FullFunctionFormat)),
+	)
+	slog.New(handler).Error("failed", "err", newStackError("boom"))
+
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	if lines[1] != "  boom" {
+		t.Errorf("expected error message line, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "    at github.com/tigorlazuardi/prettylog.newStackError writer_error_test.go:") {
+		t.Errorf("expected stack frame line, got %q", lines[2])
+	}
+}
+
+func TestErrorWriterNoErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(NewErrorWriter()),
+	)
+	slog.New(handler).Info("message", "key", "value")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestErrorWriterMultipleWrapped(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(NewErrorWriter()),
+	)
+	first := fmt.Errorf("read config: %w", errors.New("not found"))
+	second := newStackError("timeout")
+	err := fmt.Errorf("start: %w and %w", first, second)
+	slog.New(handler).Error("failed", "err", err)
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "err:\n  start: read config: not found and timeout\n      at ") {
+		t.Errorf("expected full message followed by the stack trace of a child, got:\n%s", got)
+	}
+	for _, msg := range []string{"read config", "not found", "timeout"} {
+		if n := strings.Count(got, msg); n != 1 {
+			t.Errorf("expected %q to be written once, got %d times in:\n%s", msg, n, got)
+		}
+	}
+}
+
+// cyclicError is an error unwrapping to itself.
+type cyclicError struct{ msg string }
+
+func (e *cyclicError) Error() string { return e.msg }
+func (e *cyclicError) Unwrap() error { return e }
+
+func TestErrorWriterCycle(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(NewErrorWriter()),
+	)
+	slog.New(handler).Error("failed", "err", fmt.Errorf("wrap: %w", &cyclicError{msg: "loop"}))
+
+	expected := strings.Join([]string{
+		"err:",
+		"  wrap",
+		"    loop",
+	}, "\n")
+	if got := buf.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -11,7 +11,14 @@ import (
 var DefaultFileLineWriter = NewFileLineWriter()
 
 // ShortFileLineFormat returns file path and line number with working directory trimmed.
+//
+// Results are cached per call site by the handler, see [WithFrameCacheSize]. The working directory
+// is read when a call site is first logged.
 func ShortFileLineFormat(info RecordData) string {
+	return cachedFrameFormat(info, formatShortFileLine, shortFileLine)
+}
+
+func shortFileLine(info RecordData) string {
 	wd, _ := os.Getwd()
 	s, cut := strings.CutPrefix(info.Frame.File, wd)
 	if cut {
@@ -21,7 +28,13 @@ func ShortFileLineFormat(info RecordData) string {
 }
 
 // LongFileLineFormat returns the complete file path with line number.
+//
+// Results are cached per call site by the handler, see [WithFrameCacheSize].
 func LongFileLineFormat(info RecordData) string {
+	return cachedFrameFormat(info, formatLongFileLine, longFileLine)
+}
+
+func longFileLine(info RecordData) string {
 	return info.Frame.File + ":" + strconv.Itoa(info.Frame.Line)
 }
 
@@ -34,7 +47,7 @@ type FileLineWriter struct {
 // NewFileLineWriter creates a new FileLineWriter with short format and "File" key.
 func NewFileLineWriter() *FileLineWriter {
 	return &FileLineWriter{
-		CommonWriter: NewCommonWriter(ShortFileLineFormat).WithStaticKey("File"),
+		CommonWriter: NewCommonWriter(ShortFileLineFormat).WithStaticKey("File").WithValueStyleWriter(SourceStyleWriter),
 	}
 }
 
@@ -44,6 +57,13 @@ func (fw *FileLineWriter) WithShortFormat() *FileLineWriter {
 	return fw
 }
 
+// WithModuleFormat sets the formatter to use file paths relative to their Go module.
+// See [ModuleFileLineFormat].
+func (fw *FileLineWriter) WithModuleFormat() *FileLineWriter {
+	fw.CommonWriter.Valuer = ModuleFileLineFormat
+	return fw
+}
+
 // WithLongFormat sets the formatter to use complete file paths.
 func (fw *FileLineWriter) WithLongFormat() *FileLineWriter {
 	fw.CommonWriter.Valuer = LongFileLineFormat
@@ -57,8 +77,20 @@ func (fw *FileLineWriter) WithFormat(format Formatter) *FileLineWriter {
 	return fw
 }
 
+// WithHyperlink makes the file and line a clickable OSC 8 terminal hyperlink to the URL built
+// from template, e.g. [VSCodeURLTemplate] or [RepositoryURLTemplate]. See [FileURLTemplate] for
+// the available placeholders.
+//
+// The hyperlink wraps the output of the current value styler, so set a custom styler with
+// WithValueStyleWriter or WithValueColorizer before calling WithHyperlink. Like stylers, hyperlinks are written only
+// when [RecordData.Color] is true.
+func (fw *FileLineWriter) WithHyperlink(template string) *FileLineWriter {
+	fw.CommonWriter.WithValueStyleWriter(hyperlinkStyleWriter(template, fw.CommonWriter.valueStyleWriter()))
+	return fw
+}
+
 func (fw *FileLineWriter) Write(info RecordData) {
-	if info.Frame.Func == nil {
+	if !hasCaller(info.Frame) {
 		return
 	}
 	fw.CommonWriter.Write(info)
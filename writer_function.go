@@ -12,7 +12,13 @@ var DefaultFunctionWrtier = NewFunctionWriter()
 
 // ShortFunctionFormat returns the short function name if package name matches.
 // If package name is empty or does not match, returns the full function name.
+//
+// Results are cached per call site by the handler, see [WithFrameCacheSize].
 func ShortFunctionFormat(info RecordData) string {
+	return cachedFrameFormat(info, formatShortFunction, shortFunction)
+}
+
+func shortFunction(info RecordData) string {
 	if info.PackageName == "" {
 		return info.Frame.Function
 	}
@@ -38,7 +44,7 @@ type FunctionWriter struct {
 // NewFunctionWriter creates a new FunctionWriter with short format and "Function" key.
 func NewFunctionWriter() *FunctionWriter {
 	return &FunctionWriter{
-		CommonWriter: NewCommonWriter(ShortFunctionFormat).WithStaticKey("Func"),
+		CommonWriter: NewCommonWriter(ShortFunctionFormat).WithStaticKey("Func").WithValueStyleWriter(SourceStyleWriter),
 	}
 }
 
@@ -62,9 +68,21 @@ func (fw *FunctionWriter) WithFormat(format Formatter) *FunctionWriter {
 	return fw
 }
 
+// WithHyperlink makes the function name a clickable OSC 8 terminal hyperlink to the URL built
+// from template, e.g. [VSCodeURLTemplate] or [RepositoryURLTemplate]. See [FileURLTemplate] for
+// the available placeholders.
+//
+// The hyperlink wraps the output of the current value styler, so set a custom styler with
+// WithValueStyleWriter or WithValueColorizer before calling WithHyperlink. Like stylers, hyperlinks are written only
+// when [RecordData.Color] is true.
+func (fw *FunctionWriter) WithHyperlink(template string) *FunctionWriter {
+	fw.CommonWriter.WithValueStyleWriter(hyperlinkStyleWriter(template, fw.CommonWriter.valueStyleWriter()))
+	return fw
+}
+
 func (fu FunctionWriter) Write(info RecordData) {
 	// Keep consistent with slog contract to not write anything if caller info is not available.
-	if info.Frame.Func == nil {
+	if !hasCaller(info.Frame) {
 		return
 	}
 	fu.CommonWriter.Write(info)
@@ -1,7 +1,6 @@
 package prettylog
 
 import (
-	"context"
 	"log/slog"
 
 	"github.com/tidwall/pretty"
@@ -15,11 +14,10 @@ var _ EntryWriter = (*PrettyJSONWriter)(nil)
 var DefaultPrettyJSONWriter = NewPrettyJSONWriter()
 
 // NewPrettyJSONWriter creates a new PrettyJSONWriter with default pretty-printing
-// options and terminal styling.
+// options and the styling of [RecordData.Theme].
 func NewPrettyJSONWriter() *PrettyJSONWriter {
 	return &PrettyJSONWriter{
 		options: pretty.DefaultOptions,
-		style:   pretty.TerminalStyle,
 		pool:    newLimitedPool(16 * 1024), // 16KB
 	}
 }
@@ -28,7 +26,9 @@ func NewPrettyJSONWriter() *PrettyJSONWriter {
 // It formats log attributes as colored, indented JSON, excluding standard slog keys.
 //
 // It excludes standard slog keys (time, level, message, source)
-// and formats the remaining attributes as colored JSON.
+// and formats the remaining attributes as colored JSON. Attributes and groups
+// collected by [Handler.WithAttrs] and [Handler.WithGroup] are nested the same
+// way [slog.JSONHandler] would nest them.
 type PrettyJSONWriter struct {
 	options *pretty.Options
 	style   *pretty.Style
@@ -45,6 +45,8 @@ func (pr *PrettyJSONWriter) WithPrettyOptions(opts *pretty.Options) *PrettyJSONW
 
 // WithStyle sets the color styling for JSON output.
 // This controls the colors used for different JSON elements like keys, values, etc.
+//
+// If style is nil, the attribute style of [RecordData.Theme] is used.
 func (pr *PrettyJSONWriter) WithStyle(style *pretty.Style) *PrettyJSONWriter {
 	pr.style = style
 	return pr
@@ -56,47 +58,40 @@ func (pr *PrettyJSONWriter) KeyLen(info RecordData) int {
 }
 
 // Write implements [EntryWriter] interface.
+//
+// Attributes are encoded straight into [RecordData.Buffer] in a single pass, with the same
+// output [slog.JSONHandler] formatted by [pretty.PrettyOptions] and [pretty.Color] would produce.
+// If SortKeys is set in the pretty options, the encoded attributes are sorted by [pretty.PrettyOptions]
+// afterwards.
 func (pr *PrettyJSONWriter) Write(info RecordData) {
 	if info.Buffer.Len() > 0 {
 		info.Buffer.WriteByte('\n')
 	}
-	placeholder := pr.pool.Get()
-	defer pr.pool.Put(placeholder)
-
-	opt := cloneHandlerOptions(info.HandlerOptions)
-	if opt == nil {
-		opt = &slog.HandlerOptions{}
+	var style *pretty.Style
+	if info.Color {
+		style = pr.style
+		if style == nil {
+			style = attrsStyleOf(info)
+		}
 	}
-	opt.ReplaceAttr = pr.buildReplaceAttr(opt.ReplaceAttr)
-	jsonSerializer := slog.NewJSONHandler(placeholder, opt)
-	jsonSerializer.Handle(context.Background(), info.Record)
-
-	b := placeholder.Bytes()
-	if len(b) == 3 && b[0] == '{' && b[1] == '}' && b[2] == '\n' {
-		// No attributes to log
+	if pr.options == nil || !pr.options.SortKeys {
+		if b, ok := appendJSONAttrs(info.Buffer.AvailableBuffer(), info, pr.options, style); ok {
+			info.Buffer.Write(b)
+		}
 		return
 	}
 
+	placeholder := pr.pool.Get()
+	defer pr.pool.Put(placeholder)
+	b, ok := appendJSONAttrs(placeholder.AvailableBuffer(), info, &pretty.Options{}, nil)
+	if !ok {
+		return
+	}
 	b = pretty.PrettyOptions(b, pr.options)
-	if info.Color {
-		b = pretty.Color(b, pr.style)
+	if style != nil {
+		b = pretty.Color(b, style)
 	}
 	info.Buffer.Write(b)
 }
 
 type replaceAttrFunc = func(group []string, a slog.Attr) slog.Attr
-
-func (pr *PrettyJSONWriter) buildReplaceAttr(parent replaceAttrFunc) replaceAttrFunc {
-	return func(group []string, a slog.Attr) slog.Attr {
-		if len(group) == 0 {
-			switch a.Key {
-			case slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey:
-				return slog.Attr{}
-			}
-		}
-		if parent != nil {
-			return parent(group, a)
-		}
-		return a
-	}
-}
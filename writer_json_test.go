@@ -0,0 +1,283 @@
+package prettylog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/pretty"
+)
+
+// legacyPrettyJSON is the previous implementation of [PrettyJSONWriter.Write]: the record is
+// serialized by a fresh slog.JSONHandler, then formatted and colored by pretty.
+func legacyPrettyJSON(info RecordData, opts *pretty.Options, style *pretty.Style) []byte {
+	placeholder := &bytes.Buffer{}
+	opt := cloneHandlerOptions(info.HandlerOptions)
+	if opt == nil {
+		opt = &slog.HandlerOptions{}
+	}
+	parent := opt.ReplaceAttr
+	opt.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey:
+				return slog.Attr{}
+			}
+		}
+		if parent != nil {
+			return parent(groups, a)
+		}
+		return a
+	}
+	var h slog.Handler = slog.NewJSONHandler(placeholder, opt)
+	depth := 0
+	for _, ga := range info.HandlerAttrs {
+		for ; depth < len(ga.Groups); depth++ {
+			h = h.WithGroup(ga.Groups[depth])
+		}
+		h = h.WithAttrs(ga.Attrs)
+	}
+	for ; depth < len(info.Groups); depth++ {
+		h = h.WithGroup(info.Groups[depth])
+	}
+	_ = h.Handle(context.Background(), info.Record)
+
+	b := placeholder.Bytes()
+	if string(b) == "{}\n" {
+		return nil
+	}
+	b = pretty.PrettyOptions(b, opts)
+	if style != nil {
+		b = pretty.Color(b, style)
+	}
+	return b
+}
+
+type jsonMarshalerError struct{}
+
+func (jsonMarshalerError) Error() string                { return "not used" }
+func (jsonMarshalerError) MarshalJSON() ([]byte, error) { return []byte(`{"code": 42}`), nil }
+
+type nilError struct{ msg string }
+
+func (e *nilError) Error() string { return e.msg }
+
+type tokenValuer string
+
+func (t tokenValuer) LogValue() slog.Value { return slog.StringValue(strings.Repeat("*", len(t))) }
+
+func jsonEncoderRecord(attrs ...slog.Attr) slog.Record {
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+	record.AddAttrs(attrs...)
+	return record
+}
+
+func TestAppendJSONAttrsMatchesJSONHandler(t *testing.T) {
+	var nilErr *nilError
+	records := map[string]slog.Record{
+		"scalars": jsonEncoderRecord(
+			slog.String("string", "hello"),
+			slog.Int("int", -42),
+			slog.Uint64("uint", 42),
+			slog.Bool("true", true),
+			slog.Bool("false", false),
+			slog.Duration("duration", 1500*time.Millisecond),
+			slog.Time("time", time.Date(2024, 3, 15, 14, 30, 45, 123456789, time.FixedZone("UTC+7", 7*60*60))),
+			slog.Time("year out of range", time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)),
+			slog.Any("nil", nil),
+		),
+		"floats": jsonEncoderRecord(
+			slog.Float64("float", 3.14),
+			slog.Float64("whole", 2),
+			slog.Float64("large", 1e21),
+			slog.Float64("small", 1e-7),
+			slog.Float64("negative zero", math.Copysign(0, -1)),
+			slog.Float64("nan", math.NaN()),
+			slog.Float64("inf", math.Inf(-1)),
+		),
+		"escaped strings": jsonEncoderRecord(
+			slog.String("quotes", `say "hi" \ bye`),
+			slog.String("control", "line\nbreak\ttab\r\x01\x7f"),
+			slog.String("unicode", "héllo 🌍 <html> & \u2028\u2029"),
+			slog.String("key \"with\"\nescapes", "value"),
+		),
+		"any values": jsonEncoderRecord(
+			slog.Any("error", errors.New("boom")),
+			slog.Any("marshaler error", jsonMarshalerError{}),
+			slog.Any("nil pointer error", nilErr),
+			slog.Any("short slice", []int{1, 2, 3}),
+			slog.Any("empty slice", []string{}),
+			slog.Any("long slice", strings.Split(strings.Repeat("element ", 20), " ")),
+			slog.Any("nested slices", [][]any{{1, "a"}, {true, nil}}),
+			slog.Any("slice of objects", []map[string]int{{"a": 1}, {"b": 2}}),
+			slog.Any("map", map[string]any{"z": 1, "a": []int{}, "m": map[string]any{}, "s": "x\ny"}),
+			slog.Any("struct", struct {
+				Name string `json:"name"`
+				Tags []string
+			}{"prettylog", []string{"slog", "json"}}),
+			slog.Any("func", func() {}),
+			slog.Any("valuer", tokenValuer("secret")),
+		),
+		"groups": jsonEncoderRecord(
+			slog.Group("empty"),
+			slog.Group("", slog.String("inlined", "yes"), slog.Group("", slog.Int("deep", 1))),
+			slog.Group("request", slog.String("method", "GET"), slog.Group("headers", slog.String("accept", "*/*"))),
+			slog.Any("source", &slog.Source{Function: "main.main", File: "main.go", Line: 10}),
+			slog.Group("g", slog.Any("source", &slog.Source{File: "main.go"}), slog.Any("empty source", &slog.Source{})),
+			slog.String("msg", "dropped at top level"),
+			slog.Group("g2", slog.String("msg", "kept in group")),
+		),
+	}
+
+	replaceAttr := func(groups []string, a slog.Attr) slog.Attr {
+		switch {
+		case a.Key == "drop":
+			return slog.Attr{}
+		case a.Key == "rename":
+			return slog.String("renamed", strings.Join(groups, "."))
+		case a.Key == "to group":
+			return slog.Group("replaced", slog.Int("a", 1), slog.String("drop", "x"))
+		case a.Key == "to valuer":
+			return slog.Any("valuer", tokenValuer("abc"))
+		}
+		return a
+	}
+	records["replace attr"] = jsonEncoderRecord(
+		slog.String("drop", "x"),
+		slog.String("rename", "x"),
+		slog.Group("nested", slog.String("rename", "x"), slog.String("to group", "x")),
+		slog.String("to valuer", "x"),
+		// Last, as slog.JSONHandler keeps a group that turned out empty in the group path
+		// given to ReplaceAttr for the following attributes.
+		slog.Group("only dropped", slog.String("drop", "x")),
+	)
+
+	handlerStates := map[string]func(l *slog.Logger) *slog.Logger{
+		"no state": func(l *slog.Logger) *slog.Logger { return l },
+		"attrs":    func(l *slog.Logger) *slog.Logger { return l.With("req_id", "abc", "drop", "x") },
+		"group":    func(l *slog.Logger) *slog.Logger { return l.WithGroup("http") },
+		"dropped":  func(l *slog.Logger) *slog.Logger { return l.With("drop", "x").WithGroup("g").With("drop", "y") },
+		"nested": func(l *slog.Logger) *slog.Logger {
+			return l.With("a", 1).WithGroup("g").With("b", []int{1, 2}).WithGroup("h")
+		},
+	}
+
+	options := map[string]*pretty.Options{
+		"default":    pretty.DefaultOptions,
+		"nil":        nil,
+		"tabs":       {Width: 80, Indent: "\t"},
+		"prefix":     {Width: 80, Prefix: "> ", Indent: "  "},
+		"narrow":     {Width: 24, Indent: "  "},
+		"no width":   {Indent: "    "},
+		"no indent":  {Width: 80},
+		"sort keys":  {Width: 80, Indent: "  ", SortKeys: true},
+		"sort+color": {Width: 40, Prefix: "| ", Indent: " ", SortKeys: true},
+	}
+
+	styles := map[string]*pretty.Style{"none": nil, "terminal": pretty.TerminalStyle}
+	for name, theme := range Themes {
+		styles["theme "+name] = theme.Attrs
+	}
+
+	for recordName, record := range records {
+		for stateName, state := range handlerStates {
+			for _, replace := range []bool{false, true} {
+				var captured RecordData
+				capture := New(WithWriters(writerFunc(func(info RecordData) { captured = info })))
+				if replace {
+					capture = capture.Clone(WithReplaceAttr(replaceAttr))
+				}
+				_ = state(slog.New(capture)).Handler().Handle(context.Background(), record)
+
+				for optionName, opts := range options {
+					for styleName, style := range styles {
+						name := strings.Join([]string{recordName, stateName, optionName, styleName}, "/")
+						if replace {
+							name += "/replace attr"
+						}
+						expected := legacyPrettyJSON(captured, opts, style)
+
+						buf := &bytes.Buffer{}
+						info := captured
+						info.Buffer = buf
+						info.Color = style != nil
+						NewPrettyJSONWriter().WithPrettyOptions(opts).WithStyle(style).Write(info)
+						if got := buf.Bytes(); !bytes.Equal(got, expected) {
+							t.Errorf("%s:\nexpected %q\ngot      %q", name, expected, got)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAppendJSONAttrsInvalidUTF8(t *testing.T) {
+	info := RecordData{Record: jsonEncoderRecord(slog.String("invalid", "bad\xffbyte"))}
+	b, ok := appendJSONAttrs(nil, info, &pretty.Options{}, nil)
+	if !ok {
+		t.Fatal("expected attributes to be written")
+	}
+	if expected := "{\n\"invalid\": \"bad\ufffdbyte\"\n}\n"; string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+}
+
+// writerFunc is an EntryWriter calling the function on Write.
+type writerFunc func(info RecordData)
+
+func (f writerFunc) KeyLen(info RecordData) int { return 0 }
+func (f writerFunc) Write(info RecordData)      { f(info) }
+
+func benchmarkPrettyJSONRecordData(b *testing.B, color bool) RecordData {
+	b.Helper()
+	var captured RecordData
+	handler := New(WithWriters(writerFunc(func(info RecordData) { captured = info })))
+	logger := slog.New(handler).With("service", "api", "version", 3).WithGroup("request")
+	logger.Info("message",
+		slog.String("method", "GET"),
+		slog.String("path", "/users/42"),
+		slog.Int("status", 200),
+		slog.Duration("latency", 1234*time.Microsecond),
+		slog.Group("headers", slog.String("accept", "application/json"), slog.String("user_agent", "curl/8.0")),
+		slog.Any("tags", []string{"a", "b"}),
+	)
+	captured.Buffer = &bytes.Buffer{}
+	captured.Color = color
+	return captured
+}
+
+func BenchmarkPrettyJSONWriter(b *testing.B) {
+	for _, color := range []bool{false, true} {
+		name := "plain"
+		if color {
+			name = "color"
+		}
+		info := benchmarkPrettyJSONRecordData(b, color)
+		style := pretty.TerminalStyle
+		if !color {
+			style = nil
+		}
+
+		b.Run(name+"/encoder", func(b *testing.B) {
+			w := NewPrettyJSONWriter()
+			b.ReportAllocs()
+			for b.Loop() {
+				info.Buffer.Reset()
+				w.Write(info)
+			}
+		})
+		b.Run(name+"/json-handler", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				info.Buffer.Reset()
+				info.Buffer.Write(legacyPrettyJSON(info, pretty.DefaultOptions, style))
+			}
+		})
+	}
+}
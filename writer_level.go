@@ -2,11 +2,12 @@ package prettylog
 
 // DefaultLevelValuer extracts the log level as a string from RecordData.
 // This is the default value formatter used by DefaultLevelWriter.
+// Custom level names set by [WithLevelNames] are taken into account.
 func DefaultLevelValuer(info RecordData) string {
-	return info.Record.Level.String()
+	return info.Levels.Name(info.Record.Level)
 }
 
 // DefaultLevelWriter is the default entry writer for log levels.
 // It displays the log level with background bold colored styling.
 var DefaultLevelWriter = NewCommonWriter(DefaultLevelValuer).
-	WithValueColorizer(BackgroundBoldColoredStyler)
+	WithValueStyleWriter(BackgroundBoldColoredStyleWriter)
@@ -0,0 +1,207 @@
+package prettylog
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tidwall/pretty"
+)
+
+var _ EntryWriter = (*LogfmtWriter)(nil)
+
+// DefaultLogfmtWriter is the default entry writer for logfmt output of log attributes.
+// It renders the attributes on a single line below the previous entries.
+//
+// It can be used as a drop-in replacement for [DefaultPrettyJSONWriter]:
+//
+//	handler := prettylog.New(
+//	    prettylog.ReplaceWriter(prettylog.DefaultPrettyJSONWriter, prettylog.DefaultLogfmtWriter),
+//	)
+var DefaultLogfmtWriter = NewLogfmtWriter()
+
+// NewLogfmtWriter creates a new LogfmtWriter with the styling of [RecordData.Theme].
+func NewLogfmtWriter() *LogfmtWriter {
+	return &LogfmtWriter{}
+}
+
+// LogfmtWriter is a specialized entry writer that renders log attributes
+// as a single line of `key=value` pairs.
+//
+// Attributes and groups collected by [Handler.WithAttrs] and [Handler.WithGroup] are included,
+// and grouped attributes have their keys prefixed by the group path separated by dots,
+// e.g. `http.method=GET`. Values are quoted and escaped when needed.
+// Top level attributes with the keys of the built-in fields (time, level, msg and source)
+// are skipped, as they are written by their own writers.
+type LogfmtWriter struct {
+	style  *pretty.Style
+	inline bool
+}
+
+// WithStyle sets the color styling for logfmt output.
+//
+// Keys use [pretty.Style.Key], and values use the String, Number, True, False
+// and Null colors depending on the kind of value.
+//
+// If style is nil, the attribute style of [RecordData.Theme] is used.
+func (lw *LogfmtWriter) WithStyle(style *pretty.Style) *LogfmtWriter {
+	lw.style = style
+	return lw
+}
+
+// WithInline sets whether the attributes are written on the same line as the previous
+// entry (separated by a space) instead of on a new line.
+func (lw *LogfmtWriter) WithInline(inline bool) *LogfmtWriter {
+	lw.inline = inline
+	return lw
+}
+
+// KeyLen implements [EntryWriter] interface. Always returns 0.
+func (lw *LogfmtWriter) KeyLen(info RecordData) int {
+	return 0
+}
+
+// Write implements [EntryWriter] interface.
+func (lw *LogfmtWriter) Write(info RecordData) {
+	first := true
+	info.WalkAttrs(func(groups []string, a slog.Attr) bool {
+		if len(groups) == 0 && isBuiltinKey(a.Key) {
+			// Written by the level, message, time and source writers.
+			return true
+		}
+		if first {
+			first = false
+			if info.Buffer.Len() > 0 {
+				if lw.inline {
+					info.Buffer.WriteByte(' ')
+				} else {
+					info.Buffer.WriteByte('\n')
+				}
+			}
+		} else {
+			info.Buffer.WriteByte(' ')
+		}
+		lw.writeKey(info, groups, a.Key)
+		info.Buffer.WriteByte('=')
+		lw.writeValue(info, a.Value)
+		return true
+	})
+}
+
+func (lw *LogfmtWriter) writeKey(info RecordData, groups []string, key string) {
+	lw.writeStyled(info.Buffer, info.Color, lw.styleOf(info, keyStyle), logfmtQuote(GroupedKey(groups, key)))
+}
+
+func (lw *LogfmtWriter) writeValue(info RecordData, v slog.Value) {
+	var (
+		text  string
+		style func(s *pretty.Style) [2]string
+	)
+	switch v.Kind() {
+	case slog.KindString:
+		text, style = logfmtQuote(v.String()), stringStyle
+	case slog.KindInt64:
+		text, style = strconv.FormatInt(v.Int64(), 10), numberStyle
+	case slog.KindUint64:
+		text, style = strconv.FormatUint(v.Uint64(), 10), numberStyle
+	case slog.KindFloat64:
+		text, style = strconv.FormatFloat(v.Float64(), 'g', -1, 64), numberStyle
+	case slog.KindBool:
+		if v.Bool() {
+			text, style = "true", trueStyle
+		} else {
+			text, style = "false", falseStyle
+		}
+	case slog.KindDuration:
+		text, style = v.Duration().String(), numberStyle
+	case slog.KindTime:
+		text, style = v.Time().Format(time.RFC3339Nano), stringStyle
+	default:
+		text, style = logfmtAny(v.Any())
+	}
+	lw.writeStyled(info.Buffer, info.Color, lw.styleOf(info, style), text)
+}
+
+func (lw *LogfmtWriter) styleOf(info RecordData, f func(s *pretty.Style) [2]string) [2]string {
+	if lw.style == nil {
+		return f(attrsStyleOf(info))
+	}
+	return f(lw.style)
+}
+
+func (lw *LogfmtWriter) writeStyled(buf *bytes.Buffer, colored bool, style [2]string, text string) {
+	if colored {
+		buf.WriteString(style[0])
+	}
+	buf.WriteString(text)
+	if colored {
+		buf.WriteString(style[1])
+	}
+}
+
+func keyStyle(s *pretty.Style) [2]string    { return s.Key }
+func stringStyle(s *pretty.Style) [2]string { return s.String }
+func numberStyle(s *pretty.Style) [2]string { return s.Number }
+func trueStyle(s *pretty.Style) [2]string   { return s.True }
+func falseStyle(s *pretty.Style) [2]string  { return s.False }
+func nullStyle(s *pretty.Style) [2]string   { return s.Null }
+
+// isBuiltinKey reports whether key is one of the keys of the built-in record fields,
+// which attribute writers skip at the top level like [PrettyJSONWriter] does.
+func isBuiltinKey(key string) bool {
+	switch key {
+	case slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey:
+		return true
+	}
+	return false
+}
+
+// logfmtAny formats values of [slog.KindAny] following the same precedence as
+// [slog.TextHandler]: errors, then [encoding.TextMarshaler], then fmt's %+v verb.
+func logfmtAny(v any) (string, func(s *pretty.Style) [2]string) {
+	switch v := v.(type) {
+	case nil:
+		return "null", nullStyle
+	case error:
+		return logfmtQuote(v.Error()), stringStyle
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		if err != nil {
+			return logfmtQuote("!ERROR:" + err.Error()), stringStyle
+		}
+		return logfmtQuote(string(b)), stringStyle
+	case []byte:
+		return logfmtQuote(string(v)), stringStyle
+	default:
+		return logfmtQuote(fmt.Sprintf("%+v", v)), stringStyle
+	}
+}
+
+// logfmtQuote quotes s if it is empty or contains characters
+// that would make the logfmt line ambiguous.
+func logfmtQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b <= ' ' || b == '=' || b == '"' || b == '\\' || b == 0x7f {
+				return strconv.Quote(s)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+		i += size
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+package prettylog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(l *slog.Logger) *slog.Logger
+		attrs    []any
+		expected string
+	}{
+		{
+			name:     "simple values",
+			attrs:    []any{"str", "value", "int", 42, "float", 1.5, "bool", true, "dur", time.Second},
+			expected: "str=value int=42 float=1.5 bool=true dur=1s",
+		},
+		{
+			name:     "quoted values",
+			attrs:    []any{"space", "hello world", "empty", "", "eq", "a=b", "quote", `say "hi"`, "newline", "a\nb"},
+			expected: `space="hello world" empty="" eq="a=b" quote="say \"hi\"" newline="a\nb"`,
+		},
+		{
+			name:     "error and nil",
+			attrs:    []any{"err", errors.New("boom failed"), "nil", nil},
+			expected: `err="boom failed" nil=null`,
+		},
+		{
+			name: "handler attrs and groups",
+			build: func(l *slog.Logger) *slog.Logger {
+				return l.With("req_id", "abc").WithGroup("http").With("method", "GET")
+			},
+			attrs:    []any{slog.Group("req", "path", "/")},
+			expected: "req_id=abc http.method=GET http.req.path=/",
+		},
+		{
+			name:     "no attributes",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			handler := New(
+				WithOutput(buf),
+				WithWriters(NewLogfmtWriter()),
+				WithColor(false),
+			)
+			logger := slog.New(handler)
+			if tt.build != nil {
+				logger = tt.build(logger)
+			}
+			logger.Info("message", tt.attrs...)
+
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLogfmtWriterReplaceAttr(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithWriters(NewLogfmtWriter()),
+		WithColor(false),
+		WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "secret" {
+				return slog.Attr{}
+			}
+			if len(groups) == 1 && groups[0] == "g" {
+				a.Value = slog.StringValue("replaced")
+			}
+			return a
+		}),
+	)
+	slog.New(handler).Info("message", "secret", "x", "keep", 1, slog.Group("g", "a", "b"))
+
+	expected := "keep=1 g.a=replaced"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestLogfmtWriterInline(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithWriters(DefaultMessageWriter, NewLogfmtWriter().WithInline(true)),
+		WithColor(false),
+	)
+	slog.New(handler).Info("message", "key", "value")
+
+	expected := "message key=value"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestLogfmtWriterCompactLayoutSkipsBuiltinKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithLayout(CompactLayout),
+		WithWriters(CompactLevelWriter, DefaultMessageWriter, CompactLogfmtWriter, CompactNewLineWriter),
+	)
+	slog.New(handler).Info("hello", "level", "debug", "msg", "other", "k", "v", slog.Group("g", "msg", "kept"))
+
+	if got, expected := buf.String(), "INFO hello k=v g.msg=kept\n"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -9,4 +9,4 @@ var DefaultMessageValuer = func(info RecordData) string {
 // DefaultMessageWriter is the default entry writer for log messages.
 // It displays the log message with simple color styling based on log level.
 var DefaultMessageWriter = NewCommonWriter(DefaultMessageValuer).
-	WithValueColorizer(SimpleColoredStyler)
+	WithValueStyleWriter(SimpleColoredStyleWriter)
@@ -0,0 +1,188 @@
+package prettylog
+
+import (
+	"bytes"
+	"container/list"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var _ EntryWriter = (*SourceSnippetWriter)(nil)
+
+// DefaultSourceSnippetWriter is the default entry writer for source code around the caller
+// of warning and error records.
+//
+// It is not part of [DefaultWriters]. Add it before the new line writer to use it:
+//
+//	handler := prettylog.New(
+//	    prettylog.AddWritersBefore(prettylog.DefaultNewLineWriter, prettylog.DefaultSourceSnippetWriter),
+//	)
+var DefaultSourceSnippetWriter = NewSourceSnippetWriter()
+
+// DefaultSourceCacheSize is the number of files cached by a [SourceSnippetWriter].
+const DefaultSourceCacheSize = 32
+
+// NewSourceSnippetWriter creates a new SourceSnippetWriter for records at [slog.LevelWarn] and above,
+// with 2 lines of context and a cache of [DefaultSourceCacheSize] files.
+func NewSourceSnippetWriter() *SourceSnippetWriter {
+	return &SourceSnippetWriter{
+		Level:   slog.LevelWarn,
+		Context: 2,
+		Indent:  "  ",
+		cache:   newSourceCache(DefaultSourceCacheSize),
+	}
+}
+
+// SourceSnippetWriter is a specialized entry writer for the source code around the caller of a record.
+//
+// The file given by [RecordData.Frame] is read from disk, and the caller line is written with
+// Context lines before and after it, prefixed by their line numbers. The caller line is marked
+// with ">" and styled with the level style. The lines of recently used files are kept in an LRU cache.
+//
+// Nothing is written if the file cannot be read, e.g. for binaries deployed without their sources.
+type SourceSnippetWriter struct {
+	// Level is the minimum level of records to write the source snippet for.
+	Level slog.Leveler
+	// Context is the number of lines written before and after the caller line.
+	Context int
+	// Indent is the indentation of snippet lines.
+	Indent string
+
+	cache *sourceCache
+}
+
+// WithLevel sets the minimum level of records to write the source snippet for.
+func (sw *SourceSnippetWriter) WithLevel(level slog.Leveler) *SourceSnippetWriter {
+	sw.Level = level
+	return sw
+}
+
+// WithContext sets the number of lines written before and after the caller line.
+func (sw *SourceSnippetWriter) WithContext(lines int) *SourceSnippetWriter {
+	sw.Context = lines
+	return sw
+}
+
+// WithIndent sets the indentation of snippet lines.
+func (sw *SourceSnippetWriter) WithIndent(indent string) *SourceSnippetWriter {
+	sw.Indent = indent
+	return sw
+}
+
+// WithCacheSize sets the number of files kept in the cache. A size of 0 or less disables caching.
+// Files cached so far are dropped.
+func (sw *SourceSnippetWriter) WithCacheSize(size int) *SourceSnippetWriter {
+	sw.cache = newSourceCache(size)
+	return sw
+}
+
+// KeyLen implements [EntryWriter] interface. Always returns 0.
+func (sw *SourceSnippetWriter) KeyLen(info RecordData) int {
+	return 0
+}
+
+// Write implements [EntryWriter] interface.
+func (sw *SourceSnippetWriter) Write(info RecordData) {
+	if sw.Level != nil && info.Record.Level < sw.Level.Level() {
+		return
+	}
+	if !hasCaller(info.Frame) || info.Frame.Line <= 0 {
+		return
+	}
+	lines := sw.cache.lines(info.Frame.File)
+	if info.Frame.Line > len(lines) {
+		return
+	}
+	span := max(sw.Context, 0)
+	first := max(info.Frame.Line-span, 1)
+	last := min(info.Frame.Line+span, len(lines))
+	width := len(strconv.Itoa(last))
+	for n := first; n <= last; n++ {
+		number := strconv.Itoa(n)
+		marker := "  "
+		if n == info.Frame.Line {
+			marker = "> "
+		}
+		line := marker + strings.Repeat(" ", width-len(number)) + number + " | " + lines[n-1]
+		style := faintStyle
+		if n == info.Frame.Line {
+			style = levelStyleOf(info).Text
+		}
+		if info.Buffer.Len() > 0 {
+			info.Buffer.WriteByte('\n')
+		}
+		info.Buffer.WriteString(sw.Indent)
+		writeStyledLine(info, style, line)
+	}
+}
+
+// sourceCache is an LRU cache of the lines of source files. It is safe for concurrent use.
+//
+// Files that cannot be read are cached as well, so they are not read again on every record.
+type sourceCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type sourceCacheEntry struct {
+	file  string
+	lines []string
+}
+
+func newSourceCache(size int) *sourceCache {
+	return &sourceCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// lines returns the lines of file, or nil if the file cannot be read.
+// A nil cache reads the file on every call.
+func (c *sourceCache) lines(file string) []string {
+	if c == nil || c.size <= 0 {
+		return readSourceLines(file)
+	}
+	c.mu.Lock()
+	if el, ok := c.entries[file]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+		return el.Value.(*sourceCacheEntry).lines
+	}
+	c.mu.Unlock()
+
+	// Read outside of the lock, so a slow file system does not block other records.
+	lines := readSourceLines(file)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[file]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*sourceCacheEntry).lines
+	}
+	c.entries[file] = c.order.PushFront(&sourceCacheEntry{file: file, lines: lines})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sourceCacheEntry).file)
+	}
+	return lines
+}
+
+func readSourceLines(file string) []string {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	content = bytes.TrimSuffix(content, []byte("\n"))
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,113 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestSourceSnippetWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.go")
+	source := "package main\n\nfunc main() {\n\tpanic(\"oops\")\n}\n"
+	if err := os.WriteFile(file, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		level    slog.Level
+		line     int
+		context  int
+		expected string
+	}{
+		{"context", slog.LevelError, 4, 1, "  3 | func main() {\n> 4 | \tpanic(\"oops\")\n  5 | }"},
+		{"clamped to file", slog.LevelWarn, 1, 2, "> 1 | package main\n  2 | \n  3 | func main() {"},
+		{"below level", slog.LevelInfo, 4, 1, ""},
+		{"line out of range", slog.LevelError, 10, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			NewSourceSnippetWriter().WithContext(tt.context).WithIndent("").Write(RecordData{
+				Record: slog.NewRecord(time.Now(), tt.level, "message", 0),
+				Frame:  runtime.Frame{Function: "main.main", File: file, Line: tt.line},
+				Buffer: buf,
+			})
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSourceSnippetWriterUnreadableFile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	NewSourceSnippetWriter().Write(RecordData{
+		Record: slog.NewRecord(time.Now(), slog.LevelError, "message", 0),
+		Frame:  runtime.Frame{Function: "main.main", File: filepath.Join(t.TempDir(), "missing.go"), Line: 1},
+		Buffer: buf,
+	})
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %q", buf.String())
+	}
+}
+
+func TestSourceSnippetWriterHighlight(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(New(
+		WithOutput(buf),
+		WithColor(true),
+		WithWriters(NewSourceSnippetWriter().WithContext(0), CompactNewLineWriter),
+	))
+	logger.Error("highlighted")
+
+	got := buf.String()
+	if !strings.Contains(got, "\x1b[") {
+		t.Errorf("expected colored output, got %q", got)
+	}
+	if !strings.Contains(StripANSI(got), `logger.Error("highlighted")`) {
+		t.Errorf("expected caller line in snippet, got %q", got)
+	}
+}
+
+func TestSourceCache(t *testing.T) {
+	dir := t.TempDir()
+	files := make([]string, 3)
+	for i := range files {
+		files[i] = filepath.Join(dir, string(rune('a'+i))+".go")
+		if err := os.WriteFile(files[i], []byte("line\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := newSourceCache(2)
+	cache.lines(files[0])
+	cache.lines(files[1])
+	cache.lines(files[0])
+	cache.lines(files[2])
+
+	if _, ok := cache.entries[files[1]]; ok {
+		t.Error("expected least recently used file to be evicted")
+	}
+	if _, ok := cache.entries[files[0]]; !ok {
+		t.Error("expected recently used file to be kept")
+	}
+
+	if err := os.WriteFile(files[0], []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.lines(files[0]); len(got) != 1 || got[0] != "line" {
+		t.Errorf("expected cached lines, got %q", got)
+	}
+}
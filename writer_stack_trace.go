@@ -0,0 +1,209 @@
+package prettylog
+
+import (
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+var _ EntryWriter = (*StackTraceWriter)(nil)
+
+// DefaultStackTraceWriter is the default entry writer for call stacks of error records.
+//
+// It is not part of [DefaultWriters]. Add it before the new line writer to use it:
+//
+//	handler := prettylog.New(
+//	    prettylog.AddWritersBefore(prettylog.DefaultNewLineWriter, prettylog.DefaultStackTraceWriter),
+//	)
+var DefaultStackTraceWriter = NewStackTraceWriter()
+
+// NewStackTraceWriter creates a new StackTraceWriter for records at [slog.LevelError] and above,
+// with short function and file/line formats.
+func NewStackTraceWriter() *StackTraceWriter {
+	return &StackTraceWriter{
+		Level:          slog.LevelError,
+		FunctionFormat: ShortFunctionFormat,
+		FileLineFormat: ShortFileLineFormat,
+		MaxDepth:       32,
+		Indent:         "  ",
+	}
+}
+
+// StackTraceWriter is a specialized entry writer for the call stack of the goroutine that logged the record.
+//
+// The stack is captured by [Handler.Handle] in [RecordData.Callers], so it is the full call stack
+// of the logging call and not only the single frame given by [slog.Record.PC]. Frames of log/slog and
+// prettylog are skipped. If Callers is not set, e.g. when the writer is used outside of [Handler],
+// only the frame of [slog.Record.PC] is written. Every frame is formatted by FunctionFormat and FileLineFormat, the same
+// [Formatter]s used by [FunctionWriter] and [FileLineWriter].
+//
+// Frames of the main module are styled with the level style, other frames are faint.
+type StackTraceWriter struct {
+	// Level is the minimum level of records to write the stack trace for.
+	Level slog.Leveler
+	// FunctionFormat formats the function of a stack frame given in [RecordData.Frame].
+	FunctionFormat Formatter
+	// FileLineFormat formats the file and line of a stack frame given in [RecordData.Frame].
+	FileLineFormat Formatter
+	// MaxDepth is the maximum number of frames written.
+	MaxDepth int
+	// CollapseStdlib collapses consecutive frames of the standard library and the runtime
+	// into a single line with the number of frames collapsed.
+	CollapseStdlib bool
+	// Indent is the indentation of frame lines.
+	Indent string
+}
+
+// WithLevel sets the minimum level of records to write the stack trace for.
+func (sw *StackTraceWriter) WithLevel(level slog.Leveler) *StackTraceWriter {
+	sw.Level = level
+	return sw
+}
+
+// WithFunctionFormat sets the formatter for function names of stack frames.
+func (sw *StackTraceWriter) WithFunctionFormat(f Formatter) *StackTraceWriter {
+	sw.FunctionFormat = f
+	return sw
+}
+
+// WithFileLineFormat sets the formatter for file and line of stack frames.
+func (sw *StackTraceWriter) WithFileLineFormat(f Formatter) *StackTraceWriter {
+	sw.FileLineFormat = f
+	return sw
+}
+
+// WithMaxDepth sets the maximum number of frames written.
+func (sw *StackTraceWriter) WithMaxDepth(depth int) *StackTraceWriter {
+	sw.MaxDepth = depth
+	return sw
+}
+
+// WithCollapseStdlib sets whether consecutive frames of the standard library and the runtime
+// are collapsed into a single line.
+func (sw *StackTraceWriter) WithCollapseStdlib(collapse bool) *StackTraceWriter {
+	sw.CollapseStdlib = collapse
+	return sw
+}
+
+// WithIndent sets the indentation of frame lines.
+func (sw *StackTraceWriter) WithIndent(indent string) *StackTraceWriter {
+	sw.Indent = indent
+	return sw
+}
+
+// KeyLen implements [EntryWriter] interface. Always returns 0.
+func (sw *StackTraceWriter) KeyLen(info RecordData) int {
+	return 0
+}
+
+// Write implements [EntryWriter] interface.
+func (sw *StackTraceWriter) Write(info RecordData) {
+	if sw.Level != nil && info.Record.Level < sw.Level.Level() {
+		return
+	}
+	frames := sw.callers(info)
+	collapsed := 0
+	for _, frame := range frames {
+		if sw.CollapseStdlib && isStandardPackage(packagePath(frame.Function)) {
+			collapsed++
+			continue
+		}
+		sw.writeCollapsed(info, collapsed)
+		collapsed = 0
+		sub := info
+		sub.Frame = frame
+		line := "at " + sw.FunctionFormat(sub) + " " + sw.FileLineFormat(sub)
+		style := faintStyle
+		if mainModuleRelativePath(frame.Function, frame.File) != "" {
+			style = levelStyleOf(info).Text
+		}
+		sw.writeLine(info, style, line)
+	}
+	sw.writeCollapsed(info, collapsed)
+}
+
+func (sw *StackTraceWriter) writeCollapsed(info RecordData, n int) {
+	if n == 0 {
+		return
+	}
+	line := "... " + strconv.Itoa(n) + " standard library frames"
+	if n == 1 {
+		line = "... 1 standard library frame"
+	}
+	sw.writeLine(info, faintStyle, line)
+}
+
+func (sw *StackTraceWriter) writeLine(info RecordData, style Style, line string) {
+	if info.Buffer.Len() > 0 {
+		info.Buffer.WriteByte('\n')
+	}
+	info.Buffer.WriteString(sw.Indent)
+	writeStyledLine(info, style, line)
+}
+
+// callersDepth implements the interface used by [Handler] to capture [RecordData.Callers].
+func (sw *StackTraceWriter) callersDepth(level slog.Level) int {
+	if sw.Level != nil && level < sw.Level.Level() {
+		return 0
+	}
+	// Leave room for the frames of log/slog and prettylog above the caller.
+	return sw.maxDepth() + 32
+}
+
+func (sw *StackTraceWriter) maxDepth() int {
+	if sw.MaxDepth <= 0 {
+		return 32
+	}
+	return sw.MaxDepth
+}
+
+// callers returns up to MaxDepth frames of [RecordData.Callers], starting at the caller of the record.
+//
+// If the caller is not found in the call stack (e.g. it was given by [ContextWithFrame]),
+// the frames of log/slog and prettylog are skipped instead.
+func (sw *StackTraceWriter) callers(info RecordData) []runtime.Frame {
+	pcs := info.Callers
+	if len(pcs) == 0 {
+		if info.Record.PC == 0 {
+			return nil
+		}
+		pcs = []uintptr{info.Record.PC}
+	}
+	caller := info.Frame
+	iter := runtime.CallersFrames(pcs)
+
+	var (
+		all   []runtime.Frame
+		start = -1
+	)
+	for {
+		frame, more := iter.Next()
+		if start == -1 && frame.Function == caller.Function && frame.File == caller.File && frame.Line == caller.Line {
+			start = len(all)
+		}
+		all = append(all, frame)
+		if !more {
+			break
+		}
+	}
+	if start == -1 {
+		for start = 0; start < len(all); start++ {
+			pkg := packagePath(all[start].Function)
+			if pkg != "log/slog" && pkg != prettylogPackage() {
+				break
+			}
+		}
+	}
+	all = all[start:]
+	if maxDepth := sw.maxDepth(); len(all) > maxDepth {
+		all = all[:maxDepth]
+	}
+	return all
+}
+
+// prettylogPackage returns the import path of this package.
+var prettylogPackage = sync.OnceValue(func() string {
+	return packagePath(runtime.FuncForPC(reflect.ValueOf(New).Pointer()).Name())
+})
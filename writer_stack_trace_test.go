@@ -0,0 +1,120 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func logErrorFromHelper(logger *slog.Logger) {
+	logger.Error("failed")
+}
+
+func TestStackTraceWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(DefaultMessageWriter, NewStackTraceWriter().WithFunctionFormat(FullFunctionFormat), CompactNewLineWriter),
+	))
+
+	logErrorFromHelper(logger)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("expected message and stack frames, got %q", buf.String())
+	}
+	if lines[0] != "failed" {
+		t.Errorf("expected message on first line, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "  at github.com/tigorlazuardi/prettylog.logErrorFromHelper ") {
+		t.Errorf("expected stack to start at the logging call, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "  at github.com/tigorlazuardi/prettylog.TestStackTraceWriter ") {
+		t.Errorf("expected caller of the logging function, got %q", lines[2])
+	}
+	if strings.Contains(buf.String(), "log/slog") {
+		t.Errorf("expected slog frames to be skipped, got %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.Warn("not an error")
+	if buf.String() != "not an error\n" {
+		t.Errorf("expected no stack trace below level, got %q", buf.String())
+	}
+}
+
+func TestStackTraceWriterCollapseStdlib(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(NewStackTraceWriter().WithLevel(slog.LevelInfo).WithCollapseStdlib(true).WithIndent(""), CompactNewLineWriter),
+	))
+
+	logger.Info("collapsed")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected test frame and collapsed frames, got %q", buf.String())
+	}
+	if !strings.HasSuffix(strings.Fields(lines[0])[1], ".TestStackTraceWriterCollapseStdlib") {
+		t.Errorf("unexpected first frame %q", lines[0])
+	}
+	// testing.tRunner and runtime.goexit.
+	if lines[1] != "... 2 standard library frames" {
+		t.Errorf("expected collapsed frames, got %q", lines[1])
+	}
+}
+
+func TestStackTraceWriterMaxDepth(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(NewStackTraceWriter().WithMaxDepth(1), CompactNewLineWriter),
+	))
+
+	logErrorFromHelper(logger)
+
+	if got := strings.Count(buf.String(), "at "); got != 1 {
+		t.Errorf("expected 1 frame, got %d: %q", got, buf.String())
+	}
+}
+
+func TestStackTraceWriterAsync(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithAsync(16, OverflowBlock),
+		WithWriters(NewStackTraceWriter().WithFunctionFormat(FullFunctionFormat).WithIndent(""), CompactNewLineWriter),
+	)
+	logErrorFromHelper(slog.New(handler))
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "at github.com/tigorlazuardi/prettylog.logErrorFromHelper ") {
+		t.Errorf("expected stack to start at the logging call, got %q", buf.String())
+	}
+}
+
+func TestStackTraceWriterWithoutCallers(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	info := RecordData{
+		Record: slog.NewRecord(time.Now(), slog.LevelError, "failed", pcs[0]),
+		Frame:  frame,
+		Buffer: &bytes.Buffer{},
+	}
+	NewStackTraceWriter().WithFunctionFormat(FullFunctionFormat).WithIndent("").Write(info)
+
+	if got, expected := info.Buffer.String(), "at github.com/tigorlazuardi/prettylog.TestStackTraceWriterWithoutCallers "; !strings.HasPrefix(got, expected) || strings.Contains(got, "\n") {
+		t.Errorf("expected only the frame of the record, got %q", got)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/tidwall/pretty"
 )
 
 func TestEnsureWriteDoesNotPanicWithNegativeRepeats(t *testing.T) {
@@ -26,3 +28,134 @@ func TestEnsureWriteDoesNotPanicWithNegativeRepeats(t *testing.T) {
 
 	handler.Handle(context.Background(), record)
 }
+
+func TestPrettyJSONWriterHandlerAttrsAndGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func(l *slog.Logger) *slog.Logger
+		attrs    []any
+		expected string
+	}{
+		{
+			name:     "with attrs",
+			build:    func(l *slog.Logger) *slog.Logger { return l.With("req_id", "abc") },
+			attrs:    []any{"key", "value"},
+			expected: `{"req_id":"abc","key":"value"}`,
+		},
+		{
+			name:     "with group",
+			build:    func(l *slog.Logger) *slog.Logger { return l.WithGroup("http") },
+			attrs:    []any{"method", "GET"},
+			expected: `{"http":{"method":"GET"}}`,
+		},
+		{
+			name: "attrs before and after group",
+			build: func(l *slog.Logger) *slog.Logger {
+				return l.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h")
+			},
+			attrs:    []any{"c", 3},
+			expected: `{"a":1,"g":{"b":2,"h":{"c":3}}}`,
+		},
+		{
+			name:     "empty trailing group is omitted",
+			build:    func(l *slog.Logger) *slog.Logger { return l.With("a", 1).WithGroup("g") },
+			expected: `{"a":1}`,
+		},
+		{
+			name:     "nothing to log",
+			build:    func(l *slog.Logger) *slog.Logger { return l.WithGroup("g") },
+			expected: ``,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			handler := New(
+				WithOutput(buf),
+				WithWriters(NewPrettyJSONWriter().WithPrettyOptions(&pretty.Options{Width: 80, Prefix: "", Indent: ""})),
+				WithColor(false),
+			)
+			logger := tt.build(slog.New(handler))
+			logger.Info("message", tt.attrs...)
+
+			got := strings.Join(strings.Fields(buf.String()), "")
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrettyJSONWriterReplaceAttrGroups(t *testing.T) {
+	seen := map[string][]string{}
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithWriters(DefaultPrettyJSONWriter),
+		WithColor(false),
+		WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+			seen[a.Key] = groups
+			return a
+		}),
+	)
+	logger := slog.New(handler).WithGroup("http").With("method", "GET").WithGroup("req")
+	logger.Info("message", "path", "/")
+
+	if got := strings.Join(seen["method"], "."); got != "http" {
+		t.Errorf("expected group path 'http' for handler attr, got %q", got)
+	}
+	if got := strings.Join(seen["path"], "."); got != "http.req" {
+		t.Errorf("expected group path 'http.req' for record attr, got %q", got)
+	}
+}
+
+func TestCommonWriterAlignsOnVisibleWidth(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	message := func(info RecordData) string { return info.Record.Message }
+	writers := []EntryWriter{
+		// Stylers emitting a different amount of escape bytes must not break alignment.
+		NewCommonWriter(message).WithStaticKey("plain").WithKeyColorizer(PlainStyler),
+		NewCommonWriter(message).WithStaticKey("bold").WithKeyColorizer(BackgroundBoldColoredStyler),
+		NewCommonWriter(message).WithStaticKey("名前"),
+		CompactNewLineWriter,
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "left",
+			expected: []string{"plain  msg", " bold  msg", "名前   msg"},
+		},
+		{
+			name:     "right",
+			opts:     []Option{WithKeyAlignment(KeyAlignRight)},
+			expected: []string{" plain msg", " bold  msg", "  名前 msg"},
+		},
+		{
+			name:     "fixed",
+			opts:     []Option{WithKeyWidth(4)},
+			expected: []string{"pla… msg", " bo… msg", "名前 msg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]Option{WithOutput(buf), WithColor(true), WithWriters(writers...)}, tt.opts...)
+			slog.New(New(opts...)).Info("msg")
+
+			lines := strings.Split(StripANSI(buf.String()), "\n")
+			for i, want := range tt.expected {
+				if lines[i] != want {
+					t.Errorf("line %d: expected %q, got %q", i, want, lines[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package prettylog
 
 import (
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,16 +22,61 @@ func RFC3339TimeFormat(info RecordData) string {
 	return info.Record.Time.Format(time.RFC3339)
 }
 
+// ElapsedTimeFormat formats the time elapsed since the handler was created, e.g. "+1.234s".
+// See [RecordData.Start].
+func ElapsedTimeFormat(info RecordData) string {
+	if info.Start.IsZero() {
+		return "+0s"
+	}
+	return "+" + info.Record.Time.Sub(info.Start).Round(time.Millisecond).String()
+}
+
+// DeltaTimeFormat formats the time elapsed since the previous record, e.g. "Δ12ms".
+// See [RecordData.Previous].
+func DeltaTimeFormat(info RecordData) string {
+	if info.Previous.IsZero() {
+		return "Δ0s"
+	}
+	return "Δ" + info.Record.Time.Sub(info.Previous).Round(time.Millisecond).String()
+}
+
+// HumanizedTimeFormat formats the timestamp relative to the current time, e.g. "just now",
+// "5s ago", "3m ago", "2h ago" or "4d ago". Timestamps in the future are formatted as "in 5s".
+func HumanizedTimeFormat(info RecordData) string {
+	d := time.Since(info.Record.Time)
+	suffix, prefix := " ago", ""
+	if d < 0 {
+		d, suffix, prefix = -d, "", "in "
+	}
+	var s string
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		s = strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	case d < time.Hour:
+		s = strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d < 24*time.Hour:
+		s = strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	default:
+		s = strconv.FormatInt(int64(d/(24*time.Hour)), 10) + "d"
+	}
+	return prefix + s + suffix
+}
+
 // TimeWriter is a specialized entry writer for timestamp output.
 // It extends CommonWriter with time-specific formatting options.
 type TimeWriter struct {
 	*CommonWriter
+	// Location is the time zone the timestamp is converted to before formatting.
+	// If nil, the location of the record time is used, which is usually [time.Local].
+	Location *time.Location
 }
 
 // NewTimeWriter creates a new TimeWriter with time-only format and "Time" key.
 func NewTimeWriter() *TimeWriter {
 	return &TimeWriter{
-		NewCommonWriter(TimeOnlyTimeFormat).WithStaticKey("Time"),
+		CommonWriter: NewCommonWriter(TimeOnlyTimeFormat).WithStaticKey("Time").WithValueStyleWriter(TimeStyleWriter),
 	}
 }
 
@@ -54,9 +101,67 @@ func (tw *TimeWriter) WithTimeFormat(layout string) *TimeWriter {
 	return tw
 }
 
+// WithElapsedFormat sets the time formatter to the time elapsed since the handler was created,
+// e.g. "+1.234s". See [ElapsedTimeFormat].
+func (tw *TimeWriter) WithElapsedFormat() *TimeWriter {
+	tw.CommonWriter.Valuer = ElapsedTimeFormat
+	return tw
+}
+
+// WithDeltaFormat sets the time formatter to the time elapsed since the previous record,
+// e.g. "Δ12ms". See [DeltaTimeFormat].
+func (tw *TimeWriter) WithDeltaFormat() *TimeWriter {
+	tw.CommonWriter.Valuer = DeltaTimeFormat
+	return tw
+}
+
+// WithHumanizedFormat sets the time formatter to the timestamp relative to the current time,
+// e.g. "5s ago". See [HumanizedTimeFormat].
+func (tw *TimeWriter) WithHumanizedFormat() *TimeWriter {
+	tw.CommonWriter.Valuer = HumanizedTimeFormat
+	return tw
+}
+
+// WithLocation sets the time zone the timestamp is converted to before formatting.
+func (tw *TimeWriter) WithLocation(loc *time.Location) *TimeWriter {
+	tw.Location = loc
+	return tw
+}
+
+// WithUTC converts the timestamp to UTC before formatting.
+func (tw *TimeWriter) WithUTC() *TimeWriter {
+	return tw.WithLocation(time.UTC)
+}
+
 func (tw *TimeWriter) Write(info RecordData) {
 	if info.Record.Time.IsZero() {
 		return
 	}
+	if tw.Location != nil {
+		info.Record.Time = info.Record.Time.In(tw.Location)
+	}
 	tw.CommonWriter.Write(info)
 }
+
+// recordClock tracks the start time of a handler and the time of its latest record.
+// It is shared by the handlers derived from the same [New] call.
+type recordClock struct {
+	start time.Time
+	last  atomic.Int64
+}
+
+func newRecordClock() *recordClock {
+	return &recordClock{start: time.Now()}
+}
+
+// observe records t as the latest record time, and returns the start time and the time
+// of the previous record. A nil clock returns zero times.
+func (c *recordClock) observe(t time.Time) (start, previous time.Time) {
+	if c == nil || t.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	if prev := c.last.Swap(t.UnixNano()); prev != 0 {
+		previous = time.Unix(0, prev)
+	}
+	return c.start, previous
+}
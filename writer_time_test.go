@@ -0,0 +1,99 @@
+package prettylog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func newKeylessTimeWriter() *TimeWriter {
+	return &TimeWriter{CommonWriter: NewCommonWriter(TimeOnlyTimeFormat)}
+}
+
+func TestElapsedAndDeltaTimeFormat(t *testing.T) {
+	start := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+	info := RecordData{
+		Record:   slog.NewRecord(start.Add(1234567*time.Microsecond), slog.LevelInfo, "message", 0),
+		Start:    start,
+		Previous: start.Add(1222 * time.Millisecond),
+	}
+
+	if got := ElapsedTimeFormat(info); got != "+1.235s" {
+		t.Errorf("expected elapsed %q, got %q", "+1.235s", got)
+	}
+	if got := DeltaTimeFormat(info); got != "Δ13ms" {
+		t.Errorf("expected delta %q, got %q", "Δ13ms", got)
+	}
+	if got := DeltaTimeFormat(RecordData{Record: info.Record}); got != "Δ0s" {
+		t.Errorf("expected zero delta for the first record, got %q", got)
+	}
+}
+
+func TestHumanizedTimeFormat(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		time     time.Time
+		expected string
+	}{
+		{now, "just now"},
+		{now.Add(-5 * time.Second), "5s ago"},
+		{now.Add(-3*time.Minute - time.Second), "3m ago"},
+		{now.Add(-2*time.Hour - time.Second), "2h ago"},
+		{now.Add(-4*24*time.Hour - time.Second), "4d ago"},
+		{now.Add(5*time.Minute + time.Second), "in 5m"},
+	}
+	for _, tt := range tests {
+		info := RecordData{Record: slog.NewRecord(tt.time, slog.LevelInfo, "message", 0)}
+		if got := HumanizedTimeFormat(info); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestTimeWriterLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	record := slog.NewRecord(time.Date(2024, 3, 15, 14, 30, 45, 0, loc), slog.LevelInfo, "message", 0)
+
+	buf := &bytes.Buffer{}
+	newKeylessTimeWriter().WithUTC().Write(RecordData{Record: record, Buffer: buf})
+	if got := buf.String(); got != "07:30:45" {
+		t.Errorf("expected UTC time, got %q", got)
+	}
+
+	buf.Reset()
+	newKeylessTimeWriter().WithLocation(time.FixedZone("UTC-1", -60*60)).WithRFC3339Format().
+		Write(RecordData{Record: record, Buffer: buf})
+	if got := buf.String(); got != "2024-03-15T06:30:45-01:00" {
+		t.Errorf("expected time in location, got %q", got)
+	}
+}
+
+func TestHandlerTracksRecordTimes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := New(
+		WithOutput(buf),
+		WithColor(false),
+		WithWriters(newKeylessTimeWriter().WithDeltaFormat(), CompactNewLineWriter),
+	)
+	derived := handler.WithAttrs([]slog.Attr{slog.String("key", "value")})
+
+	base := time.Now()
+	for _, r := range []struct {
+		handler slog.Handler
+		time    time.Time
+	}{
+		{handler, base},
+		{derived, base.Add(12 * time.Millisecond)},
+		{handler, base.Add(1500 * time.Millisecond)},
+	} {
+		if err := r.handler.Handle(t.Context(), slog.NewRecord(r.time, slog.LevelInfo, "message", 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := "Δ0s\nΔ12ms\nΔ1.488s\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}